@@ -104,7 +104,16 @@ func makeIndexHandler(server *_Server) _HandlerFunc {
 			m[t] = strings.ToLower(t)
 		}
 
-		w.Write(render("templates/index.html", m))
+		enums := make(map[string][]string)
+		for name, e := range store.Enums(server.Schema) {
+			enums[name] = e.Values()
+		}
+
+		w.Write(render("templates/index.html",
+			_Index{
+				Types: m,
+				Enums: enums,
+			}))
 	}
 }
 
@@ -127,19 +136,26 @@ func makeTypeHandler(server *_Server, t string) _HandlerFunc {
 		prepResponse(w, r)
 
 		opts := []options.ListOption{}
+		identity := store.ObjectIdentity(
+			fmt.Sprintf("%s/", strings.ToLower(t)))
 
 		ret, err := server.Store.List(
 			server.Context,
-			store.ObjectIdentity(
-				fmt.Sprintf("%s/", strings.ToLower(t))),
+			identity,
 			opts...)
 
 		if err != nil {
 			reportError(w, err, http.StatusBadRequest)
 			return
 		} else if ret != nil {
+			title := t + " objects"
+			total, err := store.Count(server.Context, server.Store, identity, opts...)
+			if err == nil {
+				title = fmt.Sprintf("%s (%d)", title, total)
+			}
+
 			resp, _ := json.Marshal(ret)
-			writeResponse(w, t+" objects", string(resp))
+			writeResponse(w, title, string(resp))
 		}
 	}
 }
@@ -171,6 +187,11 @@ func reportError(w http.ResponseWriter, err error, code int) {
 	http.Error(w, err.Error(), code)
 }
 
+type _Index struct {
+	Types map[string]string
+	Enums map[string][]string
+}
+
 type _Page struct {
 	Title string
 	Json  string
@@ -2,6 +2,8 @@ package cache_test
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -319,4 +321,40 @@ var _ = Describe("cache", func() {
 		Expect(err).ToNot(BeNil())
 	})
 
+	It("can be used in parallel", func() {
+		wg := sync.WaitGroup{}
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(worker int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				for i := 0; i < 10; i++ {
+					world := generated.WorldFactory()
+					world.Spec().SetName(fmt.Sprintf("%s-%d-%d", worldName, worker, i))
+
+					ret, err := cached.Create(ctx, world, cache.Expire(time.Second))
+					Expect(err).To(BeNil())
+
+					world = ret.(generated.World)
+					world.Spec().SetDescription(worldDesc)
+					_, err = cached.Update(ctx, world.Metadata().Identity(), world)
+					Expect(err).To(BeNil())
+
+					ret, err = cached.Get(ctx, world.Metadata().Identity())
+					Expect(err).To(BeNil())
+					Expect(ret).ToNot(BeNil())
+
+					err = cached.Delete(ctx, world.Metadata().Identity())
+					Expect(err).To(BeNil())
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		ret, err := mainst.List(ctx, generated.WorldKindIdentity())
+		Expect(err).To(BeNil())
+		Expect(len(ret)).To(Equal(0))
+	})
+
 })
@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/wazofski/storz/internal/logger"
@@ -18,6 +19,7 @@ type cachedStore struct {
 	Store             store.Store
 	Cache             store.Store
 	DefaultExpiration time.Duration
+	Lock              sync.Mutex
 	Policies          map[store.ObjectIdentity]time.Duration
 	Modiffies         map[store.ObjectIdentity]time.Time
 }
@@ -75,13 +77,16 @@ func (d *cachedStore) Create(
 		}
 	}
 
-	ret, err := d.Cache.Create(ctx, obj, opt...)
-	if copt.Expiration > 0 && err == nil {
-		d.Policies[ret.Metadata().Identity()] = copt.Expiration
-		d.Modiffies[ret.Metadata().Identity()] = time.Now()
+	ret, err := d.Store.Create(ctx, obj, opt...)
+	if err != nil {
+		return nil, err
 	}
 
-	return d.Store.Create(ctx, obj, opt...)
+	d.Lock.Lock()
+	d.remember(ctx, ret.Metadata().Identity(), ret, copt.Expiration)
+	d.Lock.Unlock()
+
+	return ret, nil
 }
 
 func (d *cachedStore) Update(
@@ -98,14 +103,16 @@ func (d *cachedStore) Update(
 		}
 	}
 
-	ret, err := d.Cache.Update(ctx, identity, obj, opt...)
-	if copt.Expiration > 0 && err == nil {
-		log.Printf("setting update expiration: %d", copt.Expiration)
-		d.Policies[ret.Metadata().Identity()] = copt.Expiration
-		d.Modiffies[ret.Metadata().Identity()] = time.Now()
+	ret, err := d.Store.Update(ctx, identity, obj, opt...)
+	if err != nil {
+		return nil, err
 	}
 
-	return d.Store.Update(ctx, identity, obj, opt...)
+	d.Lock.Lock()
+	d.remember(ctx, identity, ret, copt.Expiration)
+	d.Lock.Unlock()
+
+	return ret, nil
 }
 
 func (d *cachedStore) Delete(
@@ -113,13 +120,17 @@ func (d *cachedStore) Delete(
 	identity store.ObjectIdentity,
 	opt ...options.DeleteOption) error {
 
-	err := d.Cache.Delete(ctx, identity, opt...)
-	if err == nil {
-		delete(d.Policies, identity)
-		delete(d.Modiffies, identity)
+	err := d.Store.Delete(ctx, identity, opt...)
+	if err != nil {
+		return err
 	}
 
-	return d.Store.Delete(ctx, identity, opt...)
+	d.Lock.Lock()
+	defer d.Lock.Unlock()
+
+	d.forget(ctx, identity)
+
+	return nil
 }
 
 func (d *cachedStore) Get(
@@ -133,6 +144,22 @@ func (d *cachedStore) Get(
 		return nil, err
 	}
 
+	// trimmed objects are never cached
+	copt := options.CommonOptionHolderFactory()
+	for _, o := range opt {
+		err = o.ApplyFunction()(&copt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if copt.Fields != nil {
+		return existing, nil
+	}
+
+	d.Lock.Lock()
+	defer d.Lock.Unlock()
+
 	cached, cached_err := d.Cache.Get(ctx, identity)
 
 	has_expired := false
@@ -151,21 +178,49 @@ func (d *cachedStore) Get(
 	}
 
 	if has_expired || cached == nil {
-		if existing != nil && err == nil {
-			d.Policies[existing.Metadata().Identity()] = exp
-			d.Modiffies[existing.Metadata().Identity()] = time.Now()
-			if cached == nil {
-				d.Cache.Create(ctx, existing)
-			} else {
-				d.Cache.Update(ctx, identity, existing)
-			}
-		}
+		d.remember(ctx, identity, existing, exp)
 		return existing, err
 	}
 
 	return cached, cached_err
 }
 
+// the cached copy mirrors the stored object including its revision,
+// the caller is expected to hold the lock
+func (d *cachedStore) remember(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	obj store.Object,
+	exp time.Duration) {
+
+	d.Cache.Delete(ctx, identity)
+	d.Cache.Delete(ctx, obj.Metadata().Identity())
+
+	_, err := d.Cache.Create(ctx, obj)
+	if err != nil {
+		log.Printf("%s", err)
+		return
+	}
+
+	if exp > 0 {
+		d.Policies[obj.Metadata().Identity()] = exp
+		d.Modiffies[obj.Metadata().Identity()] = time.Now()
+	}
+}
+
+// the caller is expected to hold the lock
+func (d *cachedStore) forget(
+	ctx context.Context,
+	identity store.ObjectIdentity) {
+
+	existing, _ := d.Cache.Get(ctx, identity)
+	if existing != nil {
+		d.Cache.Delete(ctx, existing.Metadata().Identity())
+		delete(d.Policies, existing.Metadata().Identity())
+		delete(d.Modiffies, existing.Metadata().Identity())
+	}
+}
+
 func (d *cachedStore) List(
 	ctx context.Context,
 	identity store.ObjectIdentity,
@@ -173,3 +228,53 @@ func (d *cachedStore) List(
 
 	return d.Store.List(ctx, identity, opt...)
 }
+
+func (d *cachedStore) ListPage(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (store.Page, error) {
+
+	return store.ListPage(ctx, d.Store, identity, opt...)
+}
+
+func (d *cachedStore) Watch(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.WatchOption) (<-chan store.Event, error) {
+
+	return store.Watch(ctx, d.Store, identity, opt...)
+}
+
+func (d *cachedStore) Batch(
+	ctx context.Context,
+	ops []store.Op) (store.ObjectList, error) {
+
+	ret, err := store.Batch(ctx, d.Store, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Lock.Lock()
+	defer d.Lock.Unlock()
+
+	for i, op := range ops {
+		switch op.Type {
+		case store.OpCreate:
+			d.remember(ctx, ret[i].Metadata().Identity(), ret[i], d.DefaultExpiration)
+		case store.OpUpdate:
+			d.remember(ctx, op.Identity, ret[i], d.DefaultExpiration)
+		case store.OpDelete:
+			d.forget(ctx, op.Identity)
+		}
+	}
+
+	return ret, nil
+}
+
+func (d *cachedStore) Count(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (int, error) {
+
+	return store.Count(ctx, d.Store, identity, opt...)
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/rest"
+	"github.com/wazofski/storz/store"
+	"github.com/wazofski/storz/utils"
+)
+
+// BulkResult holds the outcome of the op at the same position,
+// ops are applied one by one so some may fail while others succeed
+type BulkResult struct {
+	Object store.Object
+	Error  error
+}
+
+// Bulk sends all ops to the REST server in a single request
+func Bulk(
+	ctx context.Context,
+	st store.Store,
+	ops []store.Op) ([]BulkResult, error) {
+
+	client, ok := st.(*restStore)
+	if !ok {
+		return nil, constants.ErrUnsupported
+	}
+
+	return client.Bulk(ctx, ops)
+}
+
+func (d *restStore) Bulk(
+	ctx context.Context,
+	ops []store.Op) ([]BulkResult, error) {
+
+	log.Printf("bulk %d", len(ops))
+
+	items := []rest.BulkItem{}
+	for _, op := range ops {
+		err := op.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		item := rest.BulkItem{
+			Op:       op.Type,
+			Identity: op.Identity,
+			Revision: op.IfRevision,
+		}
+
+		if op.Object != nil {
+			data, err := stripSerialize(op.Object)
+			if err != nil {
+				return nil, err
+			}
+
+			raw := json.RawMessage(data)
+			item.Object = &raw
+			item.Kind = op.Object.Metadata().Kind()
+		}
+
+		items = append(items, item)
+	}
+
+	content, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+
+	path, _ := url.Parse(fmt.Sprintf("%s%s", d.BaseURL, rest.BulkPath))
+	data, err := processRequest(d,
+		path,
+		content,
+		http.MethodPost,
+		newRestOptions(d).Headers)
+
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := []rest.BulkResult{}
+	err = json.Unmarshal(data, &parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []BulkResult{}
+	for _, p := range parsed {
+		res = append(res, d.bulkResult(p))
+	}
+
+	return res, nil
+}
+
+func (d *restStore) bulkResult(result rest.BulkResult) BulkResult {
+	if len(result.Error) > 0 {
+		err := errors.New(result.Error)
+		switch result.Status {
+		case http.StatusPreconditionFailed:
+			err = constants.ErrConflict
+		case http.StatusConflict:
+			err = constants.ErrUniqueViolation
+		case http.StatusUnprocessableEntity:
+			err = constants.ErrInvalidObject
+		}
+
+		return BulkResult{Error: err}
+	}
+
+	if result.Object == nil {
+		return BulkResult{}
+	}
+
+	obj, err := utils.UnmarshalObject(
+		*result.Object,
+		d.Schema,
+		utils.ObjeectKind(*result.Object))
+
+	if err != nil {
+		return BulkResult{Error: err}
+	}
+
+	return BulkResult{Object: obj}
+}
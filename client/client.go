@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -26,6 +25,7 @@ type restStore struct {
 	BaseURL     *url.URL
 	Schema      store.SchemaHolder
 	MakeRequest requestMaker
+	MakeStream  streamMaker
 	Headers     []headerOption
 }
 
@@ -64,6 +64,7 @@ func Factory(serviceUrl string, headers ...headerOption) store.Factory {
 			BaseURL:     URL,
 			Schema:      schema,
 			MakeRequest: makeHttpRequest,
+			MakeStream:  makeHttpStream,
 			Headers:     headers,
 		}
 
@@ -72,9 +73,18 @@ func Factory(serviceUrl string, headers ...headerOption) store.Factory {
 	}
 }
 
-func makeHttpRequest(path *url.URL, content []byte, requestType string, headers map[string]string) ([]byte, error) {
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+// httpClient is shared by requests and streams,
+// it accepts self-signed server certificates
+var httpClient = insecureClient()
+
+func insecureClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 
+	return &http.Client{Transport: transport}
+}
+
+func makeHttpRequest(path *url.URL, content []byte, requestType string, headers map[string]string) ([]byte, error) {
 	req, err := http.NewRequest(requestType, path.String(), strings.NewReader(string(content)))
 	if err != nil {
 		return nil, err
@@ -87,7 +97,7 @@ func makeHttpRequest(path *url.URL, content []byte, requestType string, headers
 	req.Close = true
 
 	// req.ContentLength = contentLength
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClient.Do(req)
 
 	if err != nil {
 		return nil, err
@@ -101,6 +111,18 @@ func makeHttpRequest(path *url.URL, content []byte, requestType string, headers
 		return rd, err
 	}
 
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return rd, constants.ErrConflict
+	}
+
+	if resp.StatusCode == http.StatusConflict {
+		return rd, constants.ErrUniqueViolation
+	}
+
+	if resp.StatusCode == http.StatusUnprocessableEntity {
+		return rd, constants.ErrInvalidObject
+	}
+
 	if resp.StatusCode < 200 || 300 <= resp.StatusCode {
 		return rd, fmt.Errorf("http %d", resp.StatusCode)
 	}
@@ -221,9 +243,18 @@ func listParameters(ropt restOptions) string {
 	opt := ropt.CommonOptions()
 
 	q := url.Values{}
-	if len(opt.OrderBy) > 0 {
-		q.Add(rest.OrderByArg, opt.OrderBy)
-		q.Add(rest.IncrementalArg, strconv.FormatBool(opt.OrderIncremental))
+	if len(opt.Order) > 0 {
+		keys := []string{}
+		for _, o := range opt.Order {
+			if o.Descending {
+				keys = append(keys, rest.DescendingPrefix+o.Key)
+			} else {
+				keys = append(keys, o.Key)
+			}
+		}
+		q.Add(rest.OrderByArg, strings.Join(keys, ","))
+	} else if opt.Descending {
+		q.Add(rest.IncrementalArg, "false")
 	}
 
 	if opt.PageOffset > 0 {
@@ -234,6 +265,23 @@ func listParameters(ropt restOptions) string {
 		q.Add(rest.PageSizeArg, fmt.Sprintf("%d", opt.PageSize))
 	}
 
+	if len(opt.Continue) > 0 {
+		q.Add(rest.ContinueArg, opt.Continue)
+	}
+
+	if opt.Fields != nil {
+		q.Add(rest.FieldsArg, strings.Join(opt.Fields, ","))
+	}
+
+	if opt.Labels != nil {
+		content, err := json.Marshal(opt.Labels)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		q.Add(rest.LabelsArg, string(content))
+	}
+
 	if opt.PropFilter != nil {
 		content, err := json.Marshal(opt.PropFilter)
 		if err != nil {
@@ -245,6 +293,10 @@ func listParameters(ropt restOptions) string {
 		}
 	}
 
+	if opt.Filter != nil {
+		q.Add(rest.FilterArg, opt.Filter.String())
+	}
+
 	if opt.KeyFilter != nil {
 		content, err := json.Marshal(opt.KeyFilter)
 		if err != nil {
@@ -330,6 +382,10 @@ func (d *restStore) Update(
 		return nil, err
 	}
 
+	if copt.IfRevision > 0 {
+		copt.Headers[rest.IfMatchHeader] = rest.ETag(copt.IfRevision)
+	}
+
 	data, err = processRequest(d,
 		makePathForIdentity(d.BaseURL, identity, ""),
 		data,
@@ -362,6 +418,10 @@ func (d *restStore) Delete(
 		}
 	}
 
+	if copt.IfRevision > 0 {
+		copt.Headers[rest.IfMatchHeader] = rest.ETag(copt.IfRevision)
+	}
+
 	_, err = processRequest(d,
 		makePathForIdentity(d.BaseURL, identity, ""),
 		[]byte{},
@@ -387,8 +447,15 @@ func (d *restStore) Get(
 		}
 	}
 
+	params := ""
+	if copt.Fields != nil {
+		q := url.Values{}
+		q.Add(rest.FieldsArg, strings.Join(copt.Fields, ","))
+		params = q.Encode()
+	}
+
 	resp, err := processRequest(d,
-		makePathForIdentity(d.BaseURL, identity, ""),
+		makePathForIdentity(d.BaseURL, identity, params),
 		[]byte{},
 		http.MethodGet,
 		copt.Headers)
@@ -457,8 +524,16 @@ func (d *restStore) List(
 	return marshalledResult, nil
 }
 
+// nil labels and annotations are sent as null and left unchanged,
+// empty ones clear them
+type strippedMeta struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
 type strippedObject struct {
-	Spec map[string]*json.RawMessage `json:"spec"`
+	Metadata strippedMeta                `json:"metadata"`
+	Spec     map[string]*json.RawMessage `json:"spec"`
 }
 
 func stripSerialize(object store.Object) ([]byte, error) {
@@ -471,5 +546,9 @@ func stripSerialize(object store.Object) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	obj.Metadata = strippedMeta{
+		Labels:      object.Metadata().Labels(),
+		Annotations: object.Metadata().Annotations(),
+	}
 	return json.Marshal(obj)
 }
@@ -28,7 +28,10 @@ var _ = BeforeSuite(func() {
 			rest.ActionGet, rest.ActionCreate,
 			rest.ActionDelete, rest.ActionUpdate),
 		rest.TypeMethods(generated.SecondWorldKind(),
-			rest.ActionGet, rest.ActionCreate))
+			rest.ActionGet, rest.ActionCreate),
+		rest.TypeMethods(generated.CitizenKind(),
+			rest.ActionGet, rest.ActionCreate,
+			rest.ActionDelete, rest.ActionUpdate))
 
 	cancel = srv.Listen(8000)
 
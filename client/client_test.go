@@ -1,13 +1,21 @@
 package client_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"log"
+	"net/http"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/wazofski/storz/client"
+	"github.com/wazofski/storz/filter"
 	"github.com/wazofski/storz/generated"
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/rest"
 	"github.com/wazofski/storz/store"
 	"github.com/wazofski/storz/store/options"
 )
@@ -185,4 +193,296 @@ var _ = Describe("client", func() {
 			world.Status().Description()))
 	})
 
+	It("can WATCH changes", func() {
+		wctx, wcancel := context.WithCancel(context.Background())
+		events, err := store.Watch(wctx, stc,
+			generated.WorldKindIdentity(),
+			options.Filter(filter.Eq("spec.name", "watched")),
+			client.Header("test", "watch"))
+		Expect(err).To(BeNil())
+
+		ignored := generated.WorldFactory()
+		ignored.Spec().SetName("ignored")
+		_, err = stc.Create(context.Background(), ignored)
+		Expect(err).To(BeNil())
+
+		world := generated.WorldFactory()
+		world.Spec().SetName("watched")
+		_, err = stc.Create(context.Background(), world)
+		Expect(err).To(BeNil())
+
+		world.Spec().SetDescription(worldDescription)
+		_, err = stc.Update(context.Background(),
+			generated.WorldIdentity("watched"), world)
+		Expect(err).To(BeNil())
+
+		var evt store.Event
+		Eventually(events).Should(Receive(&evt))
+		Expect(evt.Type).To(Equal(store.EventCreated))
+		Expect(evt.Object.PrimaryKey()).To(Equal("watched"))
+
+		Eventually(events).Should(Receive(&evt))
+		Expect(evt.Type).To(Equal(store.EventUpdated))
+		Expect(evt.Object.(generated.World).Spec().Description()).To(
+			Equal(worldDescription))
+
+		wcancel()
+		Eventually(events).Should(BeClosed())
+	})
+
+	It("cannot WATCH non-allowed", func() {
+		_, err := store.Watch(context.Background(), stc,
+			generated.ThirdWorldKindIdentity())
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("can use ETags", func() {
+		world := generated.WorldFactory()
+		world.Spec().SetName("etag")
+		_, err := stc.Create(context.Background(), world)
+		Expect(err).To(BeNil())
+
+		resp, err := http.Get("http://localhost:8000/world/etag")
+		Expect(err).To(BeNil())
+		resp.Body.Close()
+		Expect(resp.Header.Get(rest.ETagHeader)).To(Equal(rest.ETag(1)))
+
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:8000/world/etag", nil)
+		req.Header.Set(rest.IfNoneMatchHeader, rest.ETag(1))
+		resp, err = http.DefaultClient.Do(req)
+		Expect(err).To(BeNil())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusNotModified))
+	})
+
+	It("cannot UPDATE or DELETE with a stale ETag", func() {
+		world := generated.WorldFactory()
+		world.Spec().SetName("etag")
+
+		_, err := stc.Update(context.Background(),
+			generated.WorldIdentity("etag"), world,
+			options.IfRevision(5))
+		Expect(err).To(Equal(constants.ErrConflict))
+
+		err = stc.Delete(context.Background(),
+			generated.WorldIdentity("etag"),
+			options.IfRevision(5))
+		Expect(err).To(Equal(constants.ErrConflict))
+
+		ret, err := stc.Update(context.Background(),
+			generated.WorldIdentity("etag"), world,
+			options.IfRevision(1))
+		Expect(err).To(BeNil())
+		Expect(ret.Metadata().Revision()).To(Equal(int64(2)))
+
+		err = stc.Delete(context.Background(),
+			generated.WorldIdentity("etag"),
+			options.IfRevision(2))
+		Expect(err).To(BeNil())
+	})
+
+	It("can BULK write", func() {
+		first := generated.WorldFactory()
+		first.Spec().SetName("bulk1")
+		second := generated.WorldFactory()
+		second.Spec().SetName("bulk2")
+		another := generated.SecondWorldFactory()
+		another.Spec().SetName("bulk3")
+
+		res, err := client.Bulk(context.Background(), stc, []store.Op{
+			store.CreateOp(first),
+			store.CreateOp(second),
+			store.CreateOp(another),
+			store.CreateOp(first),
+		})
+		Expect(err).To(BeNil())
+		Expect(len(res)).To(Equal(4))
+		Expect(res[0].Error).To(BeNil())
+		Expect(res[0].Object.PrimaryKey()).To(Equal("bulk1"))
+		Expect(len(res[0].Object.Metadata().Created())).ToNot(Equal(0))
+		Expect(res[1].Error).To(BeNil())
+		Expect(res[2].Error).To(BeNil())
+		Expect(res[3].Error).ToNot(BeNil())
+
+		first = res[0].Object.(generated.World)
+		first.Spec().SetDescription("bulk")
+
+		stale := store.DeleteOp(generated.WorldIdentity("bulk2"))
+		stale.IfRevision = 5
+
+		res, err = client.Bulk(context.Background(), stc, []store.Op{
+			store.UpdateOp(first.Metadata().Identity(), first),
+			stale,
+			store.DeleteOp(generated.SecondWorldIdentity("bulk3")),
+			store.DeleteOp(generated.WorldIdentity("bulk2")),
+		})
+		Expect(err).To(BeNil())
+		Expect(len(res)).To(Equal(4))
+		Expect(res[0].Error).To(BeNil())
+		Expect(res[0].Object.(generated.World).Spec().Description()).To(Equal("bulk"))
+		Expect(res[1].Error).To(Equal(constants.ErrConflict))
+		Expect(res[2].Error).ToNot(BeNil())
+		Expect(res[3].Error).To(BeNil())
+		Expect(res[3].Object).To(BeNil())
+
+		_, err = stc.Get(context.Background(), generated.WorldIdentity("bulk2"))
+		Expect(err).ToNot(BeNil())
+
+		err = stc.Delete(context.Background(), generated.WorldIdentity("bulk1"))
+		Expect(err).To(BeNil())
+	})
+
+	It("can BULK write JSON lines", func() {
+		body := `{"op":"create","kind":"World","object":{"spec":{"name":"lines1"}}}
+{"op":"create","kind":"World","object":{"spec":{"name":"lines2"}}}
+{"op":"delete","identity":"world/lines1"}`
+
+		resp, err := http.Post("http://localhost:8000/_bulk",
+			"application/json", strings.NewReader(body))
+		Expect(err).To(BeNil())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		res := []rest.BulkResult{}
+		err = json.NewDecoder(resp.Body).Decode(&res)
+		Expect(err).To(BeNil())
+		Expect(len(res)).To(Equal(3))
+		for _, r := range res {
+			Expect(r.Status).To(Equal(http.StatusOK))
+		}
+
+		err = stc.Delete(context.Background(), generated.WorldIdentity("lines2"))
+		Expect(err).To(BeNil())
+	})
+
+	It("can return continue tokens and counts in headers", func() {
+		for _, name := range []string{"cont1", "cont2"} {
+			world := generated.WorldFactory()
+			world.Spec().SetName(name)
+			_, err := stc.Create(context.Background(), world)
+			Expect(err).To(BeNil())
+		}
+
+		resp, err := http.Get(`http://localhost:8000/world?pageSize=1&kf=["cont1","cont2"]`)
+		Expect(err).To(BeNil())
+		resp.Body.Close()
+		token := resp.Header.Get(rest.ContinueHeader)
+		Expect(len(token)).ToNot(Equal(0))
+		Expect(resp.Header.Get(rest.TotalCountHeader)).To(Equal("2"))
+
+		ret, err := stc.List(context.Background(),
+			generated.WorldKindIdentity(),
+			options.KeyFilter("cont1", "cont2"),
+			options.Continue(token))
+		Expect(err).To(BeNil())
+		Expect(len(ret)).To(Equal(1))
+		Expect(ret[0].PrimaryKey()).To(Equal("cont2"))
+
+		for _, name := range []string{"cont1", "cont2"} {
+			err = stc.Delete(context.Background(), generated.WorldIdentity(name))
+			Expect(err).To(BeNil())
+		}
+	})
+
+	It("cannot CREATE and UPDATE invalid objects", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("valid")
+		citizen.Spec().SetAge(151)
+
+		_, err := stc.Create(context.Background(), citizen)
+		Expect(err).To(Equal(constants.ErrInvalidObject))
+
+		citizen.Spec().SetAge(42)
+		_, err = stc.Create(context.Background(), citizen)
+		Expect(err).To(BeNil())
+
+		email := "valid"
+		citizen.Spec().SetEmail(&email)
+		_, err = stc.Update(context.Background(),
+			generated.CitizenIdentity("valid"), citizen)
+		Expect(err).To(Equal(constants.ErrInvalidObject))
+
+		ret, err := stc.Get(context.Background(),
+			generated.CitizenIdentity("valid"))
+		Expect(err).To(BeNil())
+		Expect(ret.(generated.Citizen).Spec().Email()).To(BeNil())
+
+		err = stc.Delete(context.Background(),
+			generated.CitizenIdentity("valid"))
+		Expect(err).To(BeNil())
+	})
+
+	It("cannot CREATE objects missing required props", func() {
+		resp, err := http.Post("http://localhost:8000/citizen",
+			"application/json",
+			strings.NewReader(`{"spec":{"email":"missing@storz"}}`))
+		Expect(err).To(BeNil())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusUnprocessableEntity))
+	})
+
+	It("cannot CREATE objects with unknown enum values", func() {
+		resp, err := http.Post("http://localhost:8000/citizen",
+			"application/json",
+			strings.NewReader(`{"spec":{"name":"enum","role":"king"}}`))
+		Expect(err).To(BeNil())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusUnprocessableEntity))
+
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("enum")
+		citizen.Spec().SetRole("king")
+
+		_, err = stc.Create(context.Background(), citizen)
+		Expect(errors.Is(err, constants.ErrInvalidObject)).To(BeTrue())
+	})
+
+	It("can keep labels on spec only updates", func() {
+		world := generated.WorldFactory()
+		world.Spec().SetName("labels")
+		world.Metadata().(store.MetaSetter).SetLabels(
+			map[string]string{"app": "storz"})
+		world.Metadata().(store.MetaSetter).SetAnnotations(
+			map[string]string{"note": "kept"})
+
+		_, err := stc.Create(context.Background(), world)
+		Expect(err).To(BeNil())
+
+		req, _ := http.NewRequest(http.MethodPut,
+			"http://localhost:8000/world/labels",
+			strings.NewReader(`{"spec":{"name":"labels","description":"raw"}}`))
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).To(BeNil())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		update := generated.WorldFactory()
+		update.Spec().SetName("labels")
+		_, err = client.Bulk(context.Background(), stc, []store.Op{
+			store.UpdateOp(generated.WorldIdentity("labels"), update),
+		})
+		Expect(err).To(BeNil())
+
+		_, err = stc.Update(context.Background(),
+			generated.WorldIdentity("labels"), update)
+		Expect(err).To(BeNil())
+
+		ret, err := stc.Get(context.Background(), generated.WorldIdentity("labels"))
+		Expect(err).To(BeNil())
+		Expect(ret.Metadata().Labels()).To(Equal(map[string]string{"app": "storz"}))
+		Expect(ret.Metadata().Annotations()).To(Equal(map[string]string{"note": "kept"}))
+
+		update.Metadata().(store.MetaSetter).SetLabels(map[string]string{})
+		_, err = stc.Update(context.Background(),
+			generated.WorldIdentity("labels"), update)
+		Expect(err).To(BeNil())
+
+		ret, err = stc.Get(context.Background(), generated.WorldIdentity("labels"))
+		Expect(err).To(BeNil())
+		Expect(ret.Metadata().Labels()).To(BeEmpty())
+		Expect(ret.Metadata().Annotations()).To(Equal(map[string]string{"note": "kept"}))
+
+		err = stc.Delete(context.Background(), generated.WorldIdentity("labels"))
+		Expect(err).To(BeNil())
+	})
 })
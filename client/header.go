@@ -18,6 +18,7 @@ type headerOption interface {
 	options.UpdateOption
 	options.DeleteOption
 	options.ListOption
+	options.WatchOption
 }
 
 func Header(key string, val string) headerOption {
@@ -56,6 +57,9 @@ func (d restHeaderOption) GetUpdateOption() options.Option {
 func (d restHeaderOption) GetListOption() options.Option {
 	return d
 }
+func (d restHeaderOption) GetWatchOption() options.Option {
+	return d
+}
 func (d restHeaderOption) GetHeaderOption() options.Option {
 	return d
 }
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/rest"
+	"github.com/wazofski/storz/store"
+	"github.com/wazofski/storz/store/options"
+	"github.com/wazofski/storz/utils"
+)
+
+const maxEventSize = 16 * 1024 * 1024
+
+type streamMaker func(ctx context.Context, path *url.URL, headers map[string]string) (io.ReadCloser, error)
+
+func makeHttpStream(ctx context.Context, path *url.URL, headers map[string]string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || 300 <= resp.StatusCode {
+		rd, _ := utils.ReadStream(resp.Body)
+		resp.Body.Close()
+		if len(rd) > 0 {
+			log.Printf("response content: %s", string(rd))
+		}
+		if resp.StatusCode == http.StatusNotImplemented {
+			return nil, constants.ErrUnsupported
+		}
+		return nil, fmt.Errorf("http %d", resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+func (d *restStore) Watch(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.WatchOption) (<-chan store.Event, error) {
+
+	log.Printf("watch %s", identity)
+
+	var err error
+	copt := newRestOptions(d)
+	for _, o := range opt {
+		err = o.ApplyFunction()(&copt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	q, _ := url.ParseQuery(listParameters(copt))
+	q.Add(rest.WatchArg, "true")
+
+	path := makePathForIdentity(d.BaseURL, identity, q.Encode())
+	path.Path = strings.ReplaceAll(path.Path, "//", "/")
+
+	headers := copt.Headers
+	headers["X-Requested-With"] = "XMLHttpRequest"
+
+	body, err := d.MakeStream(ctx, path, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(chan store.Event)
+	go func() {
+		defer close(res)
+		defer body.Close()
+
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 4096), maxEventSize)
+
+		typ := ""
+		data := ""
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				typ = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			case strings.HasPrefix(line, "data:"):
+				data = data + strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			case len(line) == 0 && len(data) > 0:
+				obj, err := utils.UnmarshalObject(
+					[]byte(data), d.Schema, utils.ObjeectKind([]byte(data)))
+				evt := store.Event{Type: store.EventType(typ), Object: obj}
+				typ, data = "", ""
+				if err != nil {
+					log.Printf("%s", err)
+					continue
+				}
+
+				select {
+				case res <- evt:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return res, nil
+}
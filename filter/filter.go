@@ -1,17 +1,342 @@
 package filter
 
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/wazofski/storz/internal/constants"
+)
+
 // eq Equal
 // ne Not equal
 // lt Less than
 // le Less than or equal
 // gt Greater than
 // ge Greater than or equal
-// in Inside an array or map
+// in Inside an array of values
 
 // and
 // or
 // not
 
-// convert to mongo
-// convert to memory
-// convert to sql
+// TimeFormat is the JSON form of timestamps, in UTC and of a fixed width
+// so that timestamps order the same way as their JSON form
+const TimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+type Operator string
+
+const (
+	OpEq  Operator = "eq"
+	OpNe  Operator = "ne"
+	OpLt  Operator = "lt"
+	OpLe  Operator = "le"
+	OpGt  Operator = "gt"
+	OpGe  Operator = "ge"
+	OpIn  Operator = "in"
+	OpAnd Operator = "and"
+	OpOr  Operator = "or"
+	OpNot Operator = "not"
+)
+
+type Expression struct {
+	Op    Operator      `json:"op"`
+	Key   string        `json:"key,omitempty"`
+	Value interface{}   `json:"value,omitempty"`
+	Exprs []*Expression `json:"exprs,omitempty"`
+}
+
+func Eq(key string, val interface{}) *Expression {
+	return compare(OpEq, key, val)
+}
+
+func Ne(key string, val interface{}) *Expression {
+	return compare(OpNe, key, val)
+}
+
+func Lt(key string, val interface{}) *Expression {
+	return compare(OpLt, key, val)
+}
+
+func Le(key string, val interface{}) *Expression {
+	return compare(OpLe, key, val)
+}
+
+func Gt(key string, val interface{}) *Expression {
+	return compare(OpGt, key, val)
+}
+
+func Ge(key string, val interface{}) *Expression {
+	return compare(OpGe, key, val)
+}
+
+func In(key string, vals ...interface{}) *Expression {
+	list := []interface{}{}
+	for _, v := range vals {
+		list = append(list, normalize(v))
+	}
+
+	return &Expression{
+		Op:    OpIn,
+		Key:   key,
+		Value: list,
+	}
+}
+
+func And(exprs ...*Expression) *Expression {
+	return &Expression{
+		Op:    OpAnd,
+		Exprs: exprs,
+	}
+}
+
+func Or(exprs ...*Expression) *Expression {
+	return &Expression{
+		Op:    OpOr,
+		Exprs: exprs,
+	}
+}
+
+func Not(expr *Expression) *Expression {
+	return &Expression{
+		Op:    OpNot,
+		Exprs: []*Expression{expr},
+	}
+}
+
+func compare(op Operator, key string, val interface{}) *Expression {
+	return &Expression{
+		Op:    op,
+		Key:   key,
+		Value: normalize(val),
+	}
+}
+
+// values are kept in their JSON form so that expressions
+// compare the same way locally and after a serialization round trip
+func normalize(val interface{}) interface{} {
+	if t, ok := val.(time.Time); ok {
+		return t.UTC().Format(TimeFormat)
+	}
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		return val
+	}
+
+	var res interface{}
+	if json.Unmarshal(data, &res) != nil {
+		return val
+	}
+
+	return res
+}
+
+func (e *Expression) IsLogical() bool {
+	return e.Op == OpAnd || e.Op == OpOr || e.Op == OpNot
+}
+
+func (e *Expression) Validate() error {
+	if e == nil {
+		return constants.ErrInvalidFilter
+	}
+
+	switch e.Op {
+	case OpEq, OpNe, OpLt, OpLe, OpGt, OpGe:
+		if len(e.Key) == 0 || len(e.Exprs) > 0 {
+			return constants.ErrInvalidFilter
+		}
+		switch e.Value.(type) {
+		case []interface{}, map[string]interface{}:
+			return constants.ErrInvalidFilter
+		}
+	case OpIn:
+		if len(e.Key) == 0 || len(e.Exprs) > 0 {
+			return constants.ErrInvalidFilter
+		}
+		if _, ok := e.Value.([]interface{}); !ok {
+			return constants.ErrInvalidFilter
+		}
+	case OpAnd, OpOr:
+		if len(e.Exprs) == 0 {
+			return constants.ErrInvalidFilter
+		}
+	case OpNot:
+		if len(e.Exprs) != 1 {
+			return constants.ErrInvalidFilter
+		}
+	default:
+		return constants.ErrInvalidFilter
+	}
+
+	for _, x := range e.Exprs {
+		err := x.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Expression) Keys() []string {
+	if !e.IsLogical() {
+		return []string{e.Key}
+	}
+
+	res := []string{}
+	for _, x := range e.Exprs {
+		res = append(res, x.Keys()...)
+	}
+
+	return res
+}
+
+func (e *Expression) String() string {
+	data, _ := json.Marshal(e)
+	return string(data)
+}
+
+func Parse(data []byte) (*Expression, error) {
+	res := &Expression{}
+	err := json.Unmarshal(data, res)
+	if err != nil {
+		return nil, err
+	}
+
+	err = res.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Match evaluates the expression against a JSON document
+func (e *Expression) Match(doc map[string]interface{}) bool {
+	switch e.Op {
+	case OpAnd:
+		for _, x := range e.Exprs {
+			if !x.Match(doc) {
+				return false
+			}
+		}
+		return true
+	case OpOr:
+		for _, x := range e.Exprs {
+			if x.Match(doc) {
+				return true
+			}
+		}
+		return false
+	case OpNot:
+		return !e.Exprs[0].Match(doc)
+	}
+
+	// missing and null values only equal nil
+	val, ok := Lookup(doc, e.Key)
+	if !ok || val == nil {
+		switch e.Op {
+		case OpEq:
+			return e.Value == nil
+		case OpNe:
+			return e.Value != nil
+		}
+		return false
+	}
+
+	switch e.Op {
+	case OpEq:
+		return Equal(val, e.Value)
+	case OpNe:
+		return !Equal(val, e.Value)
+	case OpIn:
+		for _, v := range e.Value.([]interface{}) {
+			if Equal(val, v) {
+				return true
+			}
+		}
+		return false
+	}
+
+	res, ok := Compare(val, e.Value)
+	if !ok {
+		return false
+	}
+
+	switch e.Op {
+	case OpLt:
+		return res < 0
+	case OpLe:
+		return res <= 0
+	case OpGt:
+		return res > 0
+	case OpGe:
+		return res >= 0
+	}
+
+	return false
+}
+
+func Lookup(doc map[string]interface{}, key string) (interface{}, bool) {
+	var current interface{} = doc
+	for _, t := range strings.Split(key, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[t]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+func Equal(a interface{}, b interface{}) bool {
+	res, ok := Compare(a, b)
+	return ok && res == 0
+}
+
+// Compare orders two JSON scalar values of the same type
+func Compare(a interface{}, b interface{}) (int, bool) {
+	switch av := a.(type) {
+	case float64:
+		bv, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		if av < bv {
+			return -1, true
+		}
+		if av > bv {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		bv, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(av, bv), true
+	case bool:
+		bv, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		if av == bv {
+			return 0, true
+		}
+		if !av {
+			return -1, true
+		}
+		return 1, true
+	case nil:
+		if b == nil {
+			return 0, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,102 @@
+package filter_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/wazofski/storz/filter"
+)
+
+var _ = Describe("filter", func() {
+
+	doc := map[string]interface{}{}
+	json.Unmarshal([]byte(`{
+		"spec": {
+			"name": "abc",
+			"nested": {
+				"counter": 5,
+				"alive": true
+			}
+		}
+	}`), &doc)
+
+	It("can match comparisons", func() {
+		Expect(filter.Eq("spec.name", "abc").Match(doc)).To(BeTrue())
+		Expect(filter.Ne("spec.name", "abc").Match(doc)).To(BeFalse())
+		Expect(filter.Gt("spec.nested.counter", 3).Match(doc)).To(BeTrue())
+		Expect(filter.Ge("spec.nested.counter", 5).Match(doc)).To(BeTrue())
+		Expect(filter.Lt("spec.nested.counter", 5).Match(doc)).To(BeFalse())
+		Expect(filter.Le("spec.nested.counter", 5.0).Match(doc)).To(BeTrue())
+		Expect(filter.Eq("spec.nested.alive", true).Match(doc)).To(BeTrue())
+		Expect(filter.In("spec.name", "x", "abc").Match(doc)).To(BeTrue())
+		Expect(filter.In("spec.name", "x", "y").Match(doc)).To(BeFalse())
+	})
+
+	It("can match logical expressions", func() {
+		Expect(filter.And(
+			filter.Eq("spec.name", "abc"),
+			filter.Gt("spec.nested.counter", 3)).Match(doc)).To(BeTrue())
+		Expect(filter.And(
+			filter.Eq("spec.name", "abc"),
+			filter.Gt("spec.nested.counter", 7)).Match(doc)).To(BeFalse())
+		Expect(filter.Or(
+			filter.Eq("spec.name", "def"),
+			filter.Gt("spec.nested.counter", 3)).Match(doc)).To(BeTrue())
+		Expect(filter.Not(
+			filter.Eq("spec.name", "abc")).Match(doc)).To(BeFalse())
+	})
+
+	It("can match missing values", func() {
+		Expect(filter.Eq("spec.score", 8).Match(doc)).To(BeFalse())
+		Expect(filter.Ne("spec.score", 8).Match(doc)).To(BeTrue())
+		Expect(filter.Not(filter.Gt("spec.score", 5)).Match(doc)).To(BeTrue())
+		Expect(filter.Eq("spec.score", nil).Match(doc)).To(BeTrue())
+		Expect(filter.Ne("spec.score", nil).Match(doc)).To(BeFalse())
+		Expect(filter.Eq("spec.name", nil).Match(doc)).To(BeFalse())
+		Expect(filter.Ne("spec.name", nil).Match(doc)).To(BeTrue())
+	})
+
+	It("does not compare different types", func() {
+		Expect(filter.Eq("spec.nested.counter", "5").Match(doc)).To(BeFalse())
+		Expect(filter.Gt("spec.name", 1).Match(doc)).To(BeFalse())
+	})
+
+	It("can validate expressions", func() {
+		Expect(filter.And().Validate()).ToNot(BeNil())
+		Expect(filter.Eq("", "abc").Validate()).ToNot(BeNil())
+		Expect(filter.Eq("spec.name", []string{"a"}).Validate()).ToNot(BeNil())
+		Expect(filter.Not(nil).Validate()).ToNot(BeNil())
+		Expect(filter.Or(
+			filter.Eq("spec.name", "abc"),
+			filter.In("spec.name", "a", "b")).Validate()).To(BeNil())
+	})
+
+	It("can serialize and parse expressions", func() {
+		expr := filter.And(
+			filter.Eq("spec.name", "abc"),
+			filter.Not(filter.In("spec.nested.counter", 1, 2)))
+
+		parsed, err := filter.Parse([]byte(expr.String()))
+		Expect(err).To(BeNil())
+		Expect(parsed).To(Equal(expr))
+
+		_, err = filter.Parse([]byte(`{"op":"xor"}`))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("can compare timestamps in their JSON form", func() {
+		at := time.Date(2024, 1, 1, 12, 0, 0, 500000000, time.FixedZone("", 3600))
+		expr := filter.Eq("spec.born", at)
+		Expect(expr.Value).To(Equal("2024-01-01T11:00:00.500000000Z"))
+
+		born := map[string]interface{}{
+			"spec": map[string]interface{}{
+				"born": "2024-01-01T11:00:00.000000000Z",
+			},
+		}
+		Expect(filter.Lt("spec.born", at).Match(born)).To(BeTrue())
+		Expect(filter.Gt("spec.born", at.Add(-time.Second)).Match(born)).To(BeTrue())
+	})
+})
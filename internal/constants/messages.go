@@ -5,10 +5,15 @@ import (
 )
 
 var (
-	ErrObjectNil     = errors.New("object is nil")
-	ErrInvalidMethod = errors.New("method not allowed")
-	ErrObjectExists  = errors.New("object already exists")
-	ErrNoSuchObject  = errors.New("object does not exist")
-	ErrInvalidFilter = errors.New("invalid filter key")
-	ErrInvalidPath   = errors.New("invalid request path")
+	ErrObjectNil       = errors.New("object is nil")
+	ErrInvalidMethod   = errors.New("method not allowed")
+	ErrObjectExists    = errors.New("object already exists")
+	ErrNoSuchObject    = errors.New("object does not exist")
+	ErrInvalidFilter   = errors.New("invalid filter key")
+	ErrInvalidPath     = errors.New("invalid request path")
+	ErrUnsupported     = errors.New("operation not supported")
+	ErrConflict        = errors.New("object revision conflict")
+	ErrInvalidToken    = errors.New("invalid continue token")
+	ErrUniqueViolation = errors.New("unique constraint violation")
+	ErrInvalidObject   = errors.New("invalid object")
 )
@@ -89,3 +89,56 @@ func (d *loggerStore) List(
 	}
 	return ret, err
 }
+
+func (d *loggerStore) ListPage(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (store.Page, error) {
+
+	ret, err := store.ListPage(ctx, d.Store, identity, opt...)
+	d.Logger.Object("ret", ret)
+	if err != nil {
+		d.Logger.Printf(err.Error())
+	}
+	return ret, err
+}
+
+func (d *loggerStore) Watch(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.WatchOption) (<-chan store.Event, error) {
+
+	ret, err := store.Watch(ctx, d.Store, identity, opt...)
+	if err != nil {
+		d.Logger.Printf(err.Error())
+	}
+	return ret, err
+}
+
+func (d *loggerStore) Batch(
+	ctx context.Context,
+	ops []store.Op) (store.ObjectList, error) {
+
+	ret, err := store.Batch(ctx, d.Store, ops)
+	for _, o := range ret {
+		if o != nil {
+			d.Logger.Object("ret", o)
+		}
+	}
+	if err != nil {
+		d.Logger.Printf(err.Error())
+	}
+	return ret, err
+}
+
+func (d *loggerStore) Count(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (int, error) {
+
+	ret, err := store.Count(ctx, d.Store, identity, opt...)
+	if err != nil {
+		d.Logger.Printf(err.Error())
+	}
+	return ret, err
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/wazofski/storz/store"
+)
+
+type _Change struct {
+	Type     store.EventType
+	Object   store.Object
+	Previous store.Object
+}
+
+// Batch applies the ops to a copy of the indexes
+// which replaces the live ones only when every op succeeds
+func (d *memoryStore) Batch(
+	ctx context.Context,
+	ops []store.Op) (store.ObjectList, error) {
+
+	log.Printf("batch %d", len(ops))
+
+	for _, op := range ops {
+		err := op.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d.Lock.Lock()
+	defer d.Lock.Unlock()
+
+	scratch := d.copy()
+	changes := []_Change{}
+	res := store.ObjectList{}
+
+	for _, op := range ops {
+		switch op.Type {
+		case store.OpCreate:
+			ret, err := scratch.create(op.Object)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, _Change{store.EventCreated, ret, nil})
+			res = append(res, ret.Clone())
+		case store.OpUpdate:
+			ret, existing, err := scratch.update(op.Identity, op.Object, op.IfRevision)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, _Change{store.EventUpdated, ret, existing})
+			res = append(res, ret.Clone())
+		case store.OpDelete:
+			existing, err := scratch.delete(op.Identity, op.IfRevision)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, _Change{store.EventDeleted, existing, nil})
+			res = append(res, nil)
+		}
+	}
+
+	d.IdentityIndex = scratch.IdentityIndex
+	d.PrimaryIndex = scratch.PrimaryIndex
+	d.UniqueIndex = scratch.UniqueIndex
+
+	for _, c := range changes {
+		d.Watchers.notify(c.Type, c.Object, c.Previous)
+	}
+
+	return res, nil
+}
+
+// stored objects are never modified in place
+// so copying the indexes is enough to isolate the batch
+func (d *memoryStore) copy() *memoryStore {
+	res := &memoryStore{
+		Schema:        d.Schema,
+		IdentityIndex: make(map[string]*store.Object, len(d.IdentityIndex)),
+		PrimaryIndex:  make(map[string]map[string]*store.Object, len(d.PrimaryIndex)),
+		UniqueIndex:   make(map[string]map[string]string, len(d.UniqueIndex)),
+	}
+
+	for k, v := range d.IdentityIndex {
+		res.IdentityIndex[k] = v
+	}
+
+	for kind, objects := range d.PrimaryIndex {
+		res.PrimaryIndex[kind] = make(map[string]*store.Object, len(objects))
+		for k, v := range objects {
+			res.PrimaryIndex[kind][k] = v
+		}
+	}
+
+	for name, keys := range d.UniqueIndex {
+		res.UniqueIndex[name] = make(map[string]string, len(keys))
+		for k, v := range keys {
+			res.UniqueIndex[name][k] = v
+		}
+	}
+
+	return res
+}
@@ -2,10 +2,13 @@ package memory
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 
+	"github.com/wazofski/storz/filter"
 	"github.com/wazofski/storz/internal/constants"
 	"github.com/wazofski/storz/internal/logger"
 	"github.com/wazofski/storz/store"
@@ -17,8 +20,11 @@ var log = logger.Factory("memory")
 
 type memoryStore struct {
 	Schema        store.SchemaHolder
+	Lock          sync.RWMutex
 	IdentityIndex map[string]*store.Object
 	PrimaryIndex  map[string]map[string]*store.Object
+	UniqueIndex   map[string]map[string]string
+	Watchers      *_Watchers
 }
 
 func Factory() store.Factory {
@@ -27,6 +33,8 @@ func Factory() store.Factory {
 			Schema:        schema,
 			IdentityIndex: make(map[string]*store.Object),
 			PrimaryIndex:  make(map[string]map[string]*store.Object),
+			UniqueIndex:   make(map[string]map[string]string),
+			Watchers:      newWatchers(),
 		}
 
 		return client, nil
@@ -58,28 +66,17 @@ func (d *memoryStore) Create(
 		return nil, constants.ErrObjectNil
 	}
 
-	lk := strings.ToLower(obj.Metadata().Kind())
-	path := fmt.Sprintf("%s/%s", lk, obj.PrimaryKey())
-	existing, _ := d.Get(ctx, store.ObjectIdentity(path))
-
-	if existing != nil {
-		return nil, constants.ErrObjectExists
-	}
-
-	clone := obj.Clone()
-	// log.Println(utils.PP(clone))
+	d.Lock.Lock()
+	defer d.Lock.Unlock()
 
-	// log.Printf("creating %s", obj.Metadata().Identity())
-	// log.Printf("path %s", obj.Metadata().Identity().Path())
-
-	d.IdentityIndex[obj.Metadata().Identity().Path()] = &clone
-	if d.PrimaryIndex[lk] == nil {
-		d.PrimaryIndex[lk] = make(map[string]*store.Object)
+	ret, err := d.create(obj)
+	if err != nil {
+		return nil, err
 	}
 
-	d.PrimaryIndex[lk][obj.PrimaryKey()] = &clone
+	d.Watchers.notify(store.EventCreated, ret, nil)
 
-	return clone.Clone(), nil
+	return ret.Clone(), nil
 }
 
 func (d *memoryStore) Update(
@@ -103,21 +100,17 @@ func (d *memoryStore) Update(
 		return nil, constants.ErrObjectNil
 	}
 
-	existing, _ := d.Get(ctx, identity)
-	if existing == nil {
-		return nil, constants.ErrNoSuchObject
-	}
-
-	clone := obj.Clone()
+	d.Lock.Lock()
+	defer d.Lock.Unlock()
 
-	d.IdentityIndex[obj.Metadata().Identity().Path()] = &clone
-	lk := strings.ToLower(existing.Metadata().Kind())
-	d.PrimaryIndex[lk][existing.PrimaryKey()] = nil
+	ret, existing, err := d.update(identity, obj, copt.IfRevision)
+	if err != nil {
+		return nil, err
+	}
 
-	lk = strings.ToLower(obj.Metadata().Kind())
-	d.PrimaryIndex[lk][obj.PrimaryKey()] = &clone
+	d.Watchers.notify(store.EventUpdated, ret, existing)
 
-	return clone.Clone(), err
+	return ret.Clone(), nil
 }
 
 func (d *memoryStore) Delete(
@@ -136,14 +129,15 @@ func (d *memoryStore) Delete(
 		}
 	}
 
-	existing, _ := d.Get(ctx, identity)
-	if existing == nil {
-		return constants.ErrNoSuchObject
+	d.Lock.Lock()
+	defer d.Lock.Unlock()
+
+	existing, err := d.delete(identity, copt.IfRevision)
+	if err != nil {
+		return err
 	}
 
-	d.IdentityIndex[identity.Path()] = nil
-	lk := strings.ToLower(existing.Metadata().Kind())
-	d.PrimaryIndex[lk][existing.PrimaryKey()] = nil
+	d.Watchers.notify(store.EventDeleted, existing, nil)
 
 	return nil
 }
@@ -164,11 +158,121 @@ func (d *memoryStore) Get(
 		}
 	}
 
+	d.Lock.RLock()
+	ret := d.get(identity)
+	d.Lock.RUnlock()
+
+	if ret == nil {
+		return nil, constants.ErrNoSuchObject
+	}
+
+	if copt.Fields != nil {
+		err = utils.ValidateFields(d.Schema, ret.Metadata().Kind(), copt.Fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return utils.ProjectObject(ret, d.Schema, copt.Fields)
+}
+
+// create, update, delete, get and remove expect the caller to hold the lock
+func (d *memoryStore) create(obj store.Object) (store.Object, error) {
+	lk := strings.ToLower(obj.Metadata().Kind())
+	path := fmt.Sprintf("%s/%s", lk, obj.PrimaryKey())
+	existing := d.get(store.ObjectIdentity(path))
+
+	if existing != nil {
+		return nil, constants.ErrObjectExists
+	}
+
+	err := d.checkUnique(obj, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	clone, err := utils.CopyObject(obj, d.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if clone.Metadata().Revision() < 1 {
+		clone.Metadata().(store.MetaSetter).SetRevision(1)
+	}
+
+	d.IdentityIndex[obj.Metadata().Identity().Path()] = &clone
+	if d.PrimaryIndex[lk] == nil {
+		d.PrimaryIndex[lk] = make(map[string]*store.Object)
+	}
+
+	d.PrimaryIndex[lk][obj.PrimaryKey()] = &clone
+	d.addUnique(clone)
+
+	return clone, nil
+}
+
+func (d *memoryStore) update(
+	identity store.ObjectIdentity,
+	obj store.Object,
+	revision int64) (store.Object, store.Object, error) {
+
+	existing := d.get(identity)
+	if existing == nil {
+		return nil, nil, constants.ErrNoSuchObject
+	}
+
+	if revision > 0 && revision != existing.Metadata().Revision() {
+		return nil, nil, constants.ErrConflict
+	}
+
+	err := d.checkUnique(obj, existing)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clone, err := utils.CopyObject(obj, d.Schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	clone.Metadata().(store.MetaSetter).SetRevision(
+		existing.Metadata().Revision() + 1)
+
+	d.remove(existing)
+
+	d.IdentityIndex[clone.Metadata().Identity().Path()] = &clone
+	lk := strings.ToLower(clone.Metadata().Kind())
+	if d.PrimaryIndex[lk] == nil {
+		d.PrimaryIndex[lk] = make(map[string]*store.Object)
+	}
+	d.PrimaryIndex[lk][clone.PrimaryKey()] = &clone
+	d.addUnique(clone)
+
+	return clone, existing, nil
+}
+
+func (d *memoryStore) delete(
+	identity store.ObjectIdentity,
+	revision int64) (store.Object, error) {
+
+	existing := d.get(identity)
+	if existing == nil {
+		return nil, constants.ErrNoSuchObject
+	}
+
+	if revision > 0 && revision != existing.Metadata().Revision() {
+		return nil, constants.ErrConflict
+	}
+
+	d.remove(existing)
+
+	return existing, nil
+}
+
+func (d *memoryStore) get(identity store.ObjectIdentity) store.Object {
 	// log.Printf("...GET identity index size: %d", len(d.IdentityIndex))
 
 	ret := d.IdentityIndex[identity.Path()]
 	if ret != nil {
-		return (*ret).Clone(), nil
+		return (*ret).Clone()
 	}
 
 	tokens := strings.Split(identity.Path(), "/")
@@ -179,12 +283,19 @@ func (d *memoryStore) Get(
 			// log.Printf("...GET type index exists with %d records", len(km))
 			ret = km[tokens[1]]
 			if ret != nil {
-				return (*ret).Clone(), nil
+				return (*ret).Clone()
 			}
 		}
 	}
 
-	return nil, constants.ErrNoSuchObject
+	return nil
+}
+
+func (d *memoryStore) remove(existing store.Object) {
+	delete(d.IdentityIndex, existing.Metadata().Identity().Path())
+	lk := strings.ToLower(existing.Metadata().Kind())
+	delete(d.PrimaryIndex[lk], existing.PrimaryKey())
+	d.removeUnique(existing)
 }
 
 func (d *memoryStore) List(
@@ -192,6 +303,19 @@ func (d *memoryStore) List(
 	identity store.ObjectIdentity,
 	opt ...options.ListOption) (store.ObjectList, error) {
 
+	page, err := d.ListPage(ctx, identity, opt...)
+	if err != nil {
+		return nil, err
+	}
+
+	return page.Objects, nil
+}
+
+func (d *memoryStore) ListPage(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (store.Page, error) {
+
 	log.Printf("list %s", identity)
 
 	var err error
@@ -199,12 +323,83 @@ func (d *memoryStore) List(
 	for _, o := range opt {
 		err = o.ApplyFunction()(&copt)
 		if err != nil {
-			return nil, err
+			return store.Page{}, err
 		}
 	}
 
+	token, err := store.ContinueFrom(copt)
+	if err != nil {
+		return store.Page{}, err
+	}
+
+	res, err := d.list(identity, copt)
+	if err != nil {
+		return store.Page{}, err
+	}
+
+	// sort results
+	res = listOrder(res, copt)
+	// continue after the previous page
+	res = listContinue(res, token)
+	// paginate
+	res = listPagination(res, copt.PageOffset, copt.PageSize)
+
+	page := store.Page{}
+	if store.Full(len(res), copt) {
+		page.Continue = store.NextToken(res[len(res)-1], copt)
+	}
+
+	// trim results
+	page.Objects, err = listProjection(res, d.Schema, copt.Fields)
+	if err != nil {
+		return store.Page{}, err
+	}
+
+	return page, nil
+}
+
+func (d *memoryStore) Count(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (int, error) {
+
+	log.Printf("count %s", identity)
+
+	var err error
+	copt := options.CommonOptionHolderFactory()
+	for _, o := range opt {
+		err = o.ApplyFunction()(&copt)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	res, err := d.list(identity, copt)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(res), nil
+}
+
+// list returns the filtered objects of a kind before ordering and pagination
+func (d *memoryStore) list(
+	identity store.ObjectIdentity,
+	copt options.CommonOptionHolder) (store.ObjectList, error) {
+
+	err := utils.ValidateOptions(d.Schema, identity.Type(), copt)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Lock.RLock()
 	res := store.ObjectList{}
 	everything := d.PrimaryIndex[identity.Type()]
+	for _, v := range everything {
+		res = append(res, (*v).Clone())
+	}
+	d.Lock.RUnlock()
+
 	if everything == nil {
 		return res, nil
 	}
@@ -213,27 +408,35 @@ func (d *memoryStore) List(
 		return nil, constants.ErrInvalidPath
 	}
 
-	for _, v := range everything {
-		if v == nil {
-			continue
-		}
-		res = append(res, (*v).Clone())
+	// key filter results
+	res = listPkeyFilter(res, copt.KeyFilter)
+	// filter results
+	res = listFilter(res, copt.PropFilter)
+	res = listExpressionFilter(res, copt.Filter)
+	res = listLabelFilter(res, copt.Labels)
+
+	return res, nil
+}
+
+func listProjection(
+	list store.ObjectList,
+	schema store.SchemaHolder,
+	fields []string) (store.ObjectList, error) {
+
+	if fields == nil {
+		return list, nil
 	}
 
-	if len(res) > 0 && copt.PropFilter != nil {
-		if utils.ObjectPath(res[0], copt.PropFilter.Key) == nil {
-			return nil, constants.ErrInvalidFilter
+	res := store.ObjectList{}
+	for _, o := range list {
+		ret, err := utils.ProjectObject(o, schema, fields)
+		if err != nil {
+			return nil, err
 		}
+		res = append(res, ret)
 	}
 
-	// key filter results
-	res = listPkeyFilter(res, copt.KeyFilter)
-	// filter results
-	res = listFilter(res, copt.PropFilter)
-	// sort results
-	res = listOrder(res, copt.OrderBy, copt.OrderIncremental)
-	// paginate
-	return listPagination(res, copt.PageOffset, copt.PageSize), nil
+	return res, nil
 }
 
 func listPkeyFilter(list store.ObjectList, filter *options.KeyFilterSetting) store.ObjectList {
@@ -263,9 +466,9 @@ func listFilter(list store.ObjectList, filter *options.PropFilterSetting) store.
 
 	res := store.ObjectList{}
 	for _, o := range list {
-		path := *utils.ObjectPath(o, filter.Key)
+		path := utils.ObjectPath(o, filter.Key)
 
-		if filter.Value == path {
+		if path != nil && filter.Value == *path {
 			res = append(res, o)
 		}
 	}
@@ -273,21 +476,136 @@ func listFilter(list store.ObjectList, filter *options.PropFilterSetting) store.
 	return res
 }
 
-func listOrder(list store.ObjectList, ob string, inc bool) store.ObjectList {
-	if len(ob) == 0 {
+func listLabelFilter(list store.ObjectList, labels map[string]string) store.ObjectList {
+	if labels == nil {
 		return list
 	}
 
-	sort.Slice(list, func(p, q int) bool {
-		if inc {
-			return *utils.ObjectPath(list[p], ob) < *utils.ObjectPath(list[q], ob)
+	res := store.ObjectList{}
+	for _, o := range list {
+		if store.HasLabels(o, labels) {
+			res = append(res, o)
 		}
-		return *utils.ObjectPath(list[p], ob) > *utils.ObjectPath(list[q], ob)
+	}
+
+	return res
+}
+
+func listExpressionFilter(list store.ObjectList, expr *filter.Expression) store.ObjectList {
+	if expr == nil {
+		return list
+	}
+
+	res := store.ObjectList{}
+	for _, o := range list {
+		data, err := utils.Serialize(o)
+		if err != nil {
+			continue
+		}
+
+		doc := make(map[string]interface{})
+		if json.Unmarshal(data, &doc) != nil {
+			continue
+		}
+
+		if expr.Match(doc) {
+			res = append(res, o)
+		}
+	}
+
+	return res
+}
+
+func listOrder(list store.ObjectList, copt options.CommonOptionHolder) store.ObjectList {
+	values := make(map[string][]interface{})
+	for _, o := range list {
+		values[o.PrimaryKey()] = orderValues(o, copt.Order)
+	}
+
+	sort.SliceStable(list, func(p, q int) bool {
+		return orderCompare(
+			values[list[p].PrimaryKey()], list[p].PrimaryKey(),
+			values[list[q].PrimaryKey()], list[q].PrimaryKey(),
+			copt.Order, copt.Descending) < 0
 	})
 
 	return list
 }
 
+func listContinue(list store.ObjectList, token *store.ContinueToken) store.ObjectList {
+	if token == nil {
+		return list
+	}
+
+	res := store.ObjectList{}
+	for _, o := range list {
+		cmp := orderCompare(
+			orderValues(o, token.Order), o.PrimaryKey(),
+			token.Values, token.Key,
+			token.Order, token.Descending)
+
+		if cmp > 0 {
+			res = append(res, o)
+		}
+	}
+
+	return res
+}
+
+func orderValues(obj store.Object, order []options.OrderSetting) []interface{} {
+	res := []interface{}{}
+	for _, o := range order {
+		res = append(res, store.OrderValue(obj, o.Key))
+	}
+
+	return res
+}
+
+// keys are compared in turn followed by the primary key
+// which goes in the direction of the last key
+func orderCompare(
+	a []interface{}, ak string,
+	b []interface{}, bk string,
+	order []options.OrderSetting,
+	descending bool) int {
+
+	for i, o := range order {
+		descending = o.Descending
+		res := valueCompare(a[i], b[i])
+		if descending {
+			res = -res
+		}
+		if res != 0 {
+			return res
+		}
+	}
+
+	res := strings.Compare(ak, bk)
+	if descending {
+		res = -res
+	}
+
+	return res
+}
+
+// missing values come first, values of different types
+// are ordered by their textual representation
+func valueCompare(a interface{}, b interface{}) int {
+	res, ok := filter.Compare(a, b)
+	if ok {
+		return res
+	}
+
+	switch {
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
 func listPagination(list store.ObjectList, offset int, size int) store.ObjectList {
 	lr := len(list)
 
@@ -0,0 +1,24 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/wazofski/storz/generated"
+	"github.com/wazofski/storz/memory"
+	"github.com/wazofski/storz/store"
+)
+
+func TestMemory(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "memory suite")
+}
+
+var mem store.Store
+var ctx context.Context = context.Background()
+
+var _ = BeforeSuite(func() {
+	mem = store.New(generated.Schema(), memory.Factory())
+})
@@ -0,0 +1,133 @@
+package memory_test
+
+import (
+	"fmt"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/wazofski/storz/generated"
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/store"
+	"github.com/wazofski/storz/store/options"
+)
+
+const workers = 8
+const iterations = 20
+
+func parallel(fn func(worker int)) {
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer GinkgoRecover()
+			defer wg.Done()
+			fn(worker)
+		}(w)
+	}
+	wg.Wait()
+}
+
+var _ = Describe("memory", func() {
+
+	It("can handle parallel object lifecycles", func() {
+		parallel(func(worker int) {
+			for i := 0; i < iterations; i++ {
+				name := fmt.Sprintf("w%d-%d", worker, i)
+
+				world := generated.WorldFactory()
+				world.Spec().SetName(name)
+				ret, err := mem.Create(ctx, world)
+				Expect(err).To(BeNil())
+
+				world = ret.(generated.World)
+				world.Spec().SetDescription(name)
+				_, err = mem.Update(ctx, world.Metadata().Identity(), world)
+				Expect(err).To(BeNil())
+
+				ret, err = mem.Get(ctx, generated.WorldIdentity(name))
+				Expect(err).To(BeNil())
+				Expect(ret.(generated.World).Spec().Description()).To(Equal(name))
+
+				_, err = mem.List(ctx, generated.WorldKindIdentity())
+				Expect(err).To(BeNil())
+
+				if i%2 == 0 {
+					err = mem.Delete(ctx, world.Metadata().Identity())
+					Expect(err).To(BeNil())
+
+					_, err = mem.Get(ctx, world.Metadata().Identity())
+					Expect(err).ToNot(BeNil())
+				}
+			}
+		})
+
+		ret, err := mem.List(ctx, generated.WorldKindIdentity())
+		Expect(err).To(BeNil())
+		Expect(len(ret)).To(Equal(workers * iterations / 2))
+	})
+
+	It("can create an object only once in parallel", func() {
+		created := make(chan bool, workers)
+		parallel(func(worker int) {
+			world := generated.WorldFactory()
+			world.Spec().SetName("contended")
+			_, err := mem.Create(ctx, world)
+			created <- err == nil
+		})
+		close(created)
+
+		count := 0
+		for c := range created {
+			if c {
+				count++
+			}
+		}
+		Expect(count).To(Equal(1))
+	})
+
+	It("does not lose parallel updates guarded by revisions", func() {
+		parallel(func(worker int) {
+			for i := 0; i < iterations; i++ {
+				for {
+					ret, err := mem.Get(ctx, generated.WorldIdentity("contended"))
+					Expect(err).To(BeNil())
+
+					world := ret.(generated.World)
+					world.Spec().Nested().SetCounter(world.Spec().Nested().Counter() + 1)
+
+					_, err = mem.Update(ctx,
+						world.Metadata().Identity(), world,
+						options.IfRevision(world.Metadata().Revision()))
+					if err == constants.ErrConflict {
+						continue
+					}
+					Expect(err).To(BeNil())
+					break
+				}
+			}
+		})
+
+		ret, err := mem.Get(ctx, generated.WorldIdentity("contended"))
+		Expect(err).To(BeNil())
+		world := ret.(generated.World)
+		Expect(world.Spec().Nested().Counter()).To(Equal(workers * iterations))
+		Expect(world.Metadata().Revision()).To(Equal(int64(workers*iterations + 1)))
+	})
+
+	It("can watch parallel changes", func() {
+		events, err := store.Watch(ctx, mem, generated.SecondWorldKindIdentity())
+		Expect(err).To(BeNil())
+
+		parallel(func(worker int) {
+			world := generated.SecondWorldFactory()
+			world.Spec().SetName(fmt.Sprintf("s%d", worker))
+			_, err := mem.Create(ctx, world)
+			Expect(err).To(BeNil())
+		})
+
+		for w := 0; w < workers; w++ {
+			Eventually(events).Should(Receive())
+		}
+	})
+})
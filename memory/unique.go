@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"encoding/json"
+
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/store"
+)
+
+// uniqueKey is the JSON form of the index key values of the object,
+// objects missing any of the values are not indexed
+func uniqueKey(obj store.Object, index store.Index) (string, bool) {
+	values := []interface{}{}
+	for _, k := range index.Keys {
+		val := store.OrderValue(obj, k)
+		if val == nil {
+			return "", false
+		}
+		values = append(values, val)
+	}
+
+	data, _ := json.Marshal(values)
+	return string(data), true
+}
+
+func (d *memoryStore) uniqueIndexes(obj store.Object) []store.Index {
+	res := []store.Index{}
+	for _, i := range store.Indexes(d.Schema, obj.Metadata().Kind()) {
+		if i.Unique {
+			res = append(res, i)
+		}
+	}
+
+	return res
+}
+
+// checkUnique fails when another object holds any of the unique values,
+// previous is the object being replaced, nil on create
+func (d *memoryStore) checkUnique(obj store.Object, previous store.Object) error {
+	for _, i := range d.uniqueIndexes(obj) {
+		key, ok := uniqueKey(obj, i)
+		if !ok {
+			continue
+		}
+		owner, ok := d.UniqueIndex[i.Name][key]
+		if !ok {
+			continue
+		}
+		if previous == nil || owner != previous.PrimaryKey() {
+			return constants.ErrUniqueViolation
+		}
+	}
+
+	return nil
+}
+
+func (d *memoryStore) addUnique(obj store.Object) {
+	for _, i := range d.uniqueIndexes(obj) {
+		key, ok := uniqueKey(obj, i)
+		if !ok {
+			continue
+		}
+		if d.UniqueIndex[i.Name] == nil {
+			d.UniqueIndex[i.Name] = make(map[string]string)
+		}
+		d.UniqueIndex[i.Name][key] = obj.PrimaryKey()
+	}
+}
+
+func (d *memoryStore) removeUnique(obj store.Object) {
+	for _, i := range d.uniqueIndexes(obj) {
+		key, ok := uniqueKey(obj, i)
+		if ok && d.UniqueIndex[i.Name][key] == obj.PrimaryKey() {
+			delete(d.UniqueIndex[i.Name], key)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/store"
+	"github.com/wazofski/storz/store/options"
+	"github.com/wazofski/storz/utils"
+)
+
+const watchBuffer = 100
+
+type _Subscription struct {
+	Identity store.ObjectIdentity
+	Options  options.CommonOptionHolder
+	Channel  chan store.Event
+}
+
+type _Watchers struct {
+	Lock          sync.Mutex
+	Subscriptions map[*_Subscription]bool
+}
+
+func newWatchers() *_Watchers {
+	return &_Watchers{
+		Subscriptions: make(map[*_Subscription]bool),
+	}
+}
+
+func (d *memoryStore) Watch(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.WatchOption) (<-chan store.Event, error) {
+
+	log.Printf("watch %s", identity.Path())
+
+	var err error
+	copt := options.CommonOptionHolderFactory()
+	for _, o := range opt {
+		err = o.ApplyFunction()(&copt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if identity.Type() != "id" {
+		proto := d.Schema.ObjectForKind(identity.Type())
+		if proto == nil {
+			return nil, constants.ErrInvalidPath
+		}
+
+		err = utils.ValidateOptions(d.Schema, identity.Type(), copt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sub := &_Subscription{
+		Identity: identity,
+		Options:  copt,
+		Channel:  make(chan store.Event, watchBuffer),
+	}
+
+	d.Watchers.add(sub)
+
+	go func() {
+		<-ctx.Done()
+		d.Watchers.remove(sub)
+	}()
+
+	return sub.Channel, nil
+}
+
+func (w *_Watchers) add(sub *_Subscription) {
+	w.Lock.Lock()
+	defer w.Lock.Unlock()
+
+	w.Subscriptions[sub] = true
+}
+
+func (w *_Watchers) remove(sub *_Subscription) {
+	w.Lock.Lock()
+	defer w.Lock.Unlock()
+
+	w.drop(sub)
+}
+
+func (w *_Watchers) drop(sub *_Subscription) {
+	if !w.Subscriptions[sub] {
+		return
+	}
+
+	delete(w.Subscriptions, sub)
+	close(sub.Channel)
+}
+
+func (w *_Watchers) notify(typ store.EventType, obj store.Object, previous store.Object) {
+	w.Lock.Lock()
+	defer w.Lock.Unlock()
+
+	for sub := range w.Subscriptions {
+		if !sub.matches(obj) && (previous == nil || !sub.matches(previous)) {
+			continue
+		}
+
+		select {
+		case sub.Channel <- store.Event{Type: typ, Object: obj.Clone()}:
+		default:
+			// the consumer is not keeping up
+			log.Printf("dropping watcher %s", sub.Identity.Path())
+			w.drop(sub)
+		}
+	}
+}
+
+func (sub *_Subscription) matches(obj store.Object) bool {
+	if sub.Identity.Type() == "id" {
+		return obj.Metadata().Identity().Path() == sub.Identity.Path()
+	}
+
+	if strings.ToLower(obj.Metadata().Kind()) != sub.Identity.Type() {
+		return false
+	}
+
+	if len(sub.Identity.Key()) > 0 && obj.PrimaryKey() != sub.Identity.Key() {
+		return false
+	}
+
+	list := store.ObjectList{obj}
+	list = listPkeyFilter(list, sub.Options.KeyFilter)
+	if sub.Options.PropFilter != nil {
+		path := utils.ObjectPath(obj, sub.Options.PropFilter.Key)
+		if path == nil || *path != sub.Options.PropFilter.Value {
+			return false
+		}
+	}
+	list = listExpressionFilter(list, sub.Options.Filter)
+
+	return len(list) > 0
+}
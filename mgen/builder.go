@@ -2,18 +2,28 @@ package mgen
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"go/format"
-	"html/template"
 	"log"
 	"os"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
+	"unicode"
 
 	"github.com/wazofski/storz/utils"
+	"golang.org/x/exp/slices"
 )
 
 func Generate(model string) error {
-	structs, resources := loadModel(model)
+	structs, resources, enums, err := loadModel(model)
+	if err != nil {
+		return err
+	}
 
 	imports := []string{
 		// "errors",
@@ -25,12 +35,27 @@ func Generate(model string) error {
 		"github.com/wazofski/storz/store",
 	}
 
+	if usesTime(structs) {
+		imports = append(imports, "time")
+	}
+
+	compiledResources, err := compileResources(resources, enums)
+	if err != nil {
+		return err
+	}
+
+	compiledStructs, err := compileStructs(structs, enums)
+	if err != nil {
+		return err
+	}
+
 	var b strings.Builder
 	b.WriteString(render("templates/imports.gotext", imports))
-	b.WriteString(compileResources(resources))
-	b.WriteString(compileStructs(structs))
+	b.WriteString(compiledResources)
+	b.WriteString(compiledStructs)
+	b.WriteString(compileEnums(enums))
 
-	str := strings.ReplaceAll(b.String(), "&#34;", "\"")
+	str := b.String()
 	res, err := format.Source([]byte(str))
 
 	if err != nil {
@@ -50,7 +75,12 @@ type _Interface struct {
 	Implements []string
 }
 
-func compileResources(resources []_Resource) string {
+type _Schema struct {
+	Resources []_Resource
+	Enums     []_Enum
+}
+
+func compileResources(resources []_Resource, enums []_Enum) (string, error) {
 	var b strings.Builder
 
 	for _, r := range resources {
@@ -90,21 +120,54 @@ func compileResources(resources []_Resource) string {
 			},
 		}
 
-		b.WriteString(compileStruct(s))
+		compiled, err := compileStruct(s, enums)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(compiled)
 		b.WriteString(render("templates/meta.gotext", r))
 		b.WriteString(render("templates/clone.gotext", s))
 	}
 
-	b.WriteString(render("templates/schema.gotext", resources))
+	b.WriteString(render("templates/schema.gotext",
+		_Schema{Resources: resources, Enums: enums}))
 
-	return b.String()
+	return b.String(), nil
 }
 
-func compileStructs(structs []_Struct) string {
+func compileStructs(structs []_Struct, enums []_Enum) (string, error) {
 	var b strings.Builder
 
 	for _, s := range structs {
-		b.WriteString(compileStruct(s))
+		compiled, err := compileStruct(s, enums)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(compiled)
+	}
+
+	return b.String(), nil
+}
+
+func usesTime(structs []_Struct) bool {
+	for _, s := range structs {
+		for _, p := range s.Props {
+			if strings.Contains(p.Type, "time.") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func compileEnums(enums []_Enum) string {
+	var b strings.Builder
+
+	for _, e := range enums {
+		b.WriteString(render("templates/enum.gotext", e))
 	}
 
 	return b.String()
@@ -115,11 +178,16 @@ type _Tuple struct {
 	B string
 }
 
-func compileStruct(s _Struct) string {
+func compileStruct(s _Struct, enums []_Enum) (string, error) {
 	var b strings.Builder
 	methods := []string{}
 
-	s.Props = addDefaultPropValues(s.Props)
+	props, err := defaultJsonProps(
+		s.Name, markEnumProps(addDefaultPropValues(s.Props), enums), enums)
+	if err != nil {
+		return "", err
+	}
+	s.Props = props
 
 	for _, p := range s.Props {
 		if p.Name != "Meta" {
@@ -139,7 +207,9 @@ func compileStruct(s _Struct) string {
 		}
 	}
 
-	impl := append(s.Implements, "json.Unmarshaler")
+	methods = append(methods, "Validate() error")
+	impl := append(append([]string{}, s.Embeds...), s.Implements...)
+	impl = append(impl, "json.Unmarshaler")
 
 	b.WriteString(render("templates/interface.gotext", _Interface{
 		Name:       s.Name,
@@ -149,8 +219,9 @@ func compileStruct(s _Struct) string {
 
 	b.WriteString(render("templates/structure.gotext", s))
 	b.WriteString(render("templates/unmarshall.gotext", s))
+	b.WriteString(render("templates/validate.gotext", s))
 
-	return b.String()
+	return b.String(), nil
 }
 
 func render(rpath string, data interface{}) string {
@@ -180,18 +251,50 @@ func addDefaultPropValues(props []_Prop) []_Prop {
 			continue
 		}
 
-		res = append(res, _Prop{
-			Name:    p.Name,
-			Json:    p.Json,
-			Type:    p.Type,
-			Default: typeDefault(p.Type),
-		})
+		p.Default = typeDefault(p.Type)
+		res = append(res, p)
+	}
+
+	return res
+}
+
+// defaultJsonProps renders the model default values as JSON
+// rejecting the ones the property would not accept
+func defaultJsonProps(name string, props []_Prop, enums []_Enum) ([]_Prop, error) {
+	res := []_Prop{}
+	for _, p := range props {
+		if p.HasDefault() {
+			data, err := p.defaultJson(enums)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"%s.%s has an invalid default: %s", name, p.Json, err)
+			}
+			p.DefaultJson = data
+		}
+		res = append(res, p)
+	}
+
+	return res, nil
+}
+
+func markEnumProps(props []_Prop, enums []_Enum) []_Prop {
+	res := []_Prop{}
+	for _, p := range props {
+		for _, e := range enums {
+			if p.StrippedType() == e.Name {
+				p.EnumType = true
+			}
+		}
+		res = append(res, p)
 	}
 
 	return res
 }
 
 func typeDefault(tp string) string {
+	if strings.HasPrefix(tp, "*") {
+		return "nil"
+	}
 	if strings.HasPrefix(tp, "[]") {
 		return fmt.Sprintf("%s {}", tp)
 	}
@@ -208,11 +311,227 @@ func typeDefault(tp string) string {
 		return "0"
 	case "float":
 		return "0"
+	case "store.Time":
+		return "store.Time{}"
+	case "time.Duration":
+		return "time.Duration(0)"
 	default:
 		return fmt.Sprintf("%sFactory()", tp)
 	}
 }
 
+// Constants name the enum values after the enum
+func (e _Enum) Constants() []_Tuple {
+	res := []_Tuple{}
+	for _, v := range e.Values {
+		res = append(res, _Tuple{
+			A: e.Name + enumConstant(v),
+			B: v,
+		})
+	}
+
+	return res
+}
+
+func enumConstant(value string) string {
+	words := strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	res := ""
+	for _, w := range words {
+		res += capitalize(w)
+	}
+
+	return res
+}
+
+func (u _Prop) HasDefault() bool {
+	return u.Value != nil
+}
+
+func (u _Prop) defaultJson(enums []_Enum) (string, error) {
+	val, err := defaultValue(u.Type, u.Value)
+	if err != nil {
+		return "", err
+	}
+
+	err = checkDefaultType(u.Type, val, enums)
+	if err != nil {
+		return "", err
+	}
+
+	err = u.checkDefaultRules(val)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(val)
+	return string(data), err
+}
+
+// checkDefaultType rejects default values the generated
+// structures would fail to unmarshal
+func checkDefaultType(tp string, val interface{}, enums []_Enum) error {
+	ok := true
+	switch {
+	case strings.HasPrefix(tp, "*"):
+		return checkDefaultType(tp[1:], val, enums)
+	case tp == "[]byte":
+		_, ok = val.([]byte)
+	case strings.HasPrefix(tp, "[]"):
+		var list []interface{}
+		list, ok = val.([]interface{})
+		for _, v := range list {
+			err := checkDefaultType(tp[2:], v, enums)
+			if err != nil {
+				return err
+			}
+		}
+	case strings.HasPrefix(tp, "map["):
+		var m map[string]interface{}
+		m, ok = val.(map[string]interface{})
+		for _, v := range m {
+			err := checkDefaultType(tp[strings.Index(tp, "]")+1:], v, enums)
+			if err != nil {
+				return err
+			}
+		}
+	case tp == "string":
+		_, ok = val.(string)
+	case tp == "bool":
+		_, ok = val.(bool)
+	case tp == "int":
+		_, ok = val.(int)
+	case tp == "float", tp == "float64":
+		switch val.(type) {
+		case int, float64:
+		default:
+			ok = false
+		}
+	}
+
+	for _, e := range enums {
+		if e.Name == tp {
+			str, isString := val.(string)
+			ok = isString && slices.Contains(e.Values, str)
+		}
+	}
+
+	if !ok {
+		return fmt.Errorf("%v is not of type %s", val, tp)
+	}
+
+	return nil
+}
+
+// checkDefaultRules rejects default values breaking the rules of the property
+func (u _Prop) checkDefaultRules(val interface{}) error {
+	v := reflect.ValueOf(val)
+
+	if u.Min != nil || u.Max != nil {
+		num := 0.0
+		switch v.Kind() {
+		case reflect.Int, reflect.Int64:
+			num = float64(v.Int())
+		case reflect.Float64:
+			num = v.Float()
+		default:
+			return fmt.Errorf("%v is not a number", val)
+		}
+		if u.Min != nil && num < *u.Min {
+			return fmt.Errorf("%v is less than %v", val, *u.Min)
+		}
+		if u.Max != nil && num > *u.Max {
+			return fmt.Errorf("%v is greater than %v", val, *u.Max)
+		}
+	}
+
+	if u.MinLength != nil || u.MaxLength != nil {
+		switch v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map:
+		default:
+			return fmt.Errorf("%v has no length", val)
+		}
+		if u.MinLength != nil && v.Len() < *u.MinLength {
+			return fmt.Errorf("%v is shorter than %d", val, *u.MinLength)
+		}
+		if u.MaxLength != nil && v.Len() > *u.MaxLength {
+			return fmt.Errorf("%v is longer than %d", val, *u.MaxLength)
+		}
+	}
+
+	if len(u.Pattern) > 0 {
+		re, err := regexp.Compile(u.Pattern)
+		if err != nil {
+			return err
+		}
+		str, ok := val.(string)
+		if !ok || !re.MatchString(str) {
+			return fmt.Errorf("%v does not match %s", val, u.Pattern)
+		}
+	}
+
+	if len(u.Enum) > 0 && !slices.Contains(u.Enum, fmt.Sprint(val)) {
+		return fmt.Errorf("%v is not one of %v", val, u.Enum)
+	}
+
+	return nil
+}
+
+// defaultValue converts the model values of types
+// whose JSON form differs from their YAML form
+func defaultValue(tp string, val interface{}) (interface{}, error) {
+	var err error
+	switch {
+	case strings.HasPrefix(tp, "*"):
+		return defaultValue(tp[1:], val)
+	case tp == "[]byte":
+		if str, ok := val.(string); ok {
+			return []byte(str), nil
+		}
+	case strings.HasPrefix(tp, "[]"):
+		if list, ok := val.([]interface{}); ok {
+			res := []interface{}{}
+			for _, v := range list {
+				v, err = defaultValue(tp[2:], v)
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, v)
+			}
+			return res, nil
+		}
+	case strings.HasPrefix(tp, "map["):
+		if m, ok := val.(map[string]interface{}); ok {
+			res := make(map[string]interface{})
+			for k, v := range m {
+				res[k], err = defaultValue(tp[strings.Index(tp, "]")+1:], v)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return res, nil
+		}
+	case tp == "time.Duration":
+		if str, ok := val.(string); ok {
+			return time.ParseDuration(str)
+		}
+	}
+
+	return val, nil
+}
+
+func (s _Struct) HasDefaults() bool {
+	for _, p := range s.Props {
+		if p.HasDefault() {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (u _Prop) IsMap() bool {
 	if len(u.Type) < 3 {
 		return false
@@ -222,14 +541,22 @@ func (u _Prop) IsMap() bool {
 }
 
 func (u _Prop) IsArray() bool {
-	if len(u.Type) < 2 {
+	if len(u.Type) < 2 || u.Type == "[]byte" {
 		return false
 	}
 
 	return u.Type[:2] == "[]"
 }
 
+// optional properties are nil and left out of the JSON until set
+func (u _Prop) IsOptional() bool {
+	return strings.HasPrefix(u.Type, "*")
+}
+
 func (u _Prop) StrippedType() string {
+	if u.IsOptional() {
+		return u.Type[1:]
+	}
 	if u.IsMap() {
 		return u.Type[strings.LastIndex(u.Type, "]")+1:]
 	}
@@ -242,3 +569,100 @@ func (u _Prop) StrippedType() string {
 func (u _Prop) StrippedDefault() string {
 	return typeDefault(u.StrippedType())
 }
+
+// Rules are the checks of the property declared in the model
+// as bodies of functions returning their error
+func (u _Prop) Rules() []string {
+	checks := []string{}
+	val := fmt.Sprintf("*entity.%s_", u.Name)
+
+	if u.Min != nil {
+		checks = append(checks,
+			fmt.Sprintf("store.CheckMin(%q, float64(%s), %s)",
+				u.Json, val, formatFloat(*u.Min)))
+	}
+	if u.Max != nil {
+		checks = append(checks,
+			fmt.Sprintf("store.CheckMax(%q, float64(%s), %s)",
+				u.Json, val, formatFloat(*u.Max)))
+	}
+	if u.MinLength != nil {
+		checks = append(checks,
+			fmt.Sprintf("store.CheckMinLength(%q, len(%s), %d)",
+				u.Json, val, *u.MinLength))
+	}
+	if u.MaxLength != nil {
+		checks = append(checks,
+			fmt.Sprintf("store.CheckMaxLength(%q, len(%s), %d)",
+				u.Json, val, *u.MaxLength))
+	}
+	if len(u.Pattern) > 0 {
+		checks = append(checks,
+			fmt.Sprintf("store.CheckPattern(%q, %s, %q)",
+				u.Json, val, u.Pattern))
+	}
+	if len(u.Enum) > 0 {
+		values := []string{}
+		for _, e := range u.Enum {
+			values = append(values, strconv.Quote(e))
+		}
+		checks = append(checks,
+			fmt.Sprintf("store.CheckEnum(%q, %s, %s)",
+				u.Json, val, strings.Join(values, ", ")))
+	}
+
+	res := []string{}
+	if u.IsOptional() {
+		// optional properties are required to be set and checked when they are
+		if u.Required {
+			res = append(res,
+				fmt.Sprintf("return store.CheckRequired(%q, entity.%s_)", u.Json, u.Name))
+		}
+		if u.EnumType {
+			checks = append(checks,
+				fmt.Sprintf("store.ValidateNested(%q, %s)", u.Json, val))
+		}
+		for _, c := range checks {
+			res = append(res,
+				fmt.Sprintf("if entity.%s_ == nil {\nreturn nil\n}\nreturn %s", u.Name, c))
+		}
+
+		return res
+	}
+
+	if u.Required {
+		res = append(res,
+			fmt.Sprintf("return store.CheckRequired(%q, %s)", u.Json, val))
+	}
+
+	for _, c := range checks {
+		res = append(res, "return "+c)
+	}
+
+	if u.IsStructure() || u.EnumType {
+		res = append(res,
+			fmt.Sprintf("return store.ValidateNested(%q, %s)", u.Json, val))
+	}
+
+	return res
+}
+
+// IsStructure tells if the property holds modeled structures
+func (u _Prop) IsStructure() bool {
+	if u.EnumType {
+		return false
+	}
+
+	tp := u.StrippedType()
+	switch tp {
+	case "string", "bool", "int", "float", "float64", "[]byte",
+		"store.Time", "time.Duration":
+		return false
+	}
+
+	return !strings.Contains(tp, ".")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -2,9 +2,10 @@ package mgen
 
 import (
 	"fmt"
-	"log"
 	"strings"
 
+	"github.com/wazofski/storz/store"
+	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v3"
 )
 
@@ -13,10 +14,29 @@ import (
 // }
 
 type _Prop struct {
-	Name    string `yaml:"name"`
-	Type    string `yaml:"type"`
-	Json    string
-	Default string
+	Name        string      `yaml:"name"`
+	Type        string      `yaml:"type"`
+	Unique      bool        `yaml:"unique,omitempty"`
+	Optional    bool        `yaml:"optional,omitempty"`
+	Required    bool        `yaml:"required,omitempty"`
+	Min         *float64    `yaml:"min,omitempty"`
+	Max         *float64    `yaml:"max,omitempty"`
+	MinLength   *int        `yaml:"minLength,omitempty"`
+	MaxLength   *int        `yaml:"maxLength,omitempty"`
+	Pattern     string      `yaml:"pattern,omitempty"`
+	Enum        []string    `yaml:"enum,omitempty"`
+	Value       interface{} `yaml:"default,omitempty"`
+	Json        string
+	Default     string `yaml:"-"`
+	DefaultJson string `yaml:"-"`
+	EnumType    bool   `yaml:"-"`
+}
+
+type _Index struct {
+	Name   string   `yaml:"name,omitempty"`
+	Keys   []string `yaml:"keys"`
+	Types  []string `yaml:"-"`
+	Unique bool     `yaml:"unique,omitempty"`
 }
 
 type _Type struct {
@@ -26,7 +46,10 @@ type _Type struct {
 	Status string `yaml:"status,omitempty"`
 	Pkey   string `yaml:"primarykey,omitempty"`
 	// ApiMethods []_ApiMethod `yaml:"apimethods,omitempty"`
-	Props []_Prop `yaml:"properties,omitempty"`
+	Props   []_Prop  `yaml:"properties,omitempty"`
+	Indexes []_Index `yaml:"indexes,omitempty"`
+	Values  []string `yaml:"values,omitempty"`
+	Embeds  []string `yaml:"embeds,omitempty"`
 }
 
 type _Model struct {
@@ -40,11 +63,18 @@ type _Struct struct {
 	Props      []_Prop
 }
 
-type _Resource struct {
+type _Enum struct {
 	Name   string
-	Spec   string
-	Status string
-	Pkey   string
+	Values []string
+}
+
+type _Resource struct {
+	Name      string
+	Spec      string
+	Status    string
+	Pkey      string
+	Indexes   []_Index
+	Optionals []string
 	// ApiMethods []_ApiMethod
 }
 
@@ -52,22 +82,35 @@ func (r _Resource) IdentityPrefix() string {
 	return strings.ToLower(r.Name)
 }
 
-func loadModel(path string) ([]_Struct, []_Resource) {
+func loadModel(path string) ([]_Struct, []_Resource, []_Enum, error) {
 	yamls := yamlFiles(path)
 	structs := []_Struct{}
 	resources := []_Resource{}
+	enums := []_Enum{}
 
 	for _, y := range yamls {
 		model, err := readModel(y)
 		if err != nil {
-			log.Fatal(err)
+			return nil, nil, nil, err
 		}
 
 		for _, m := range model.Types {
 			if m.Kind == "Struct" {
 				structs = append(structs, _Struct{
-					Name:  m.Name,
-					Props: capitalizeProps(m.Props),
+					Name:   m.Name,
+					Props:  capitalizeProps(m.Props),
+					Embeds: m.Embeds,
+				})
+				continue
+			}
+			if m.Kind == "Enum" {
+				if len(m.Values) == 0 {
+					return nil, nil, nil, fmt.Errorf("enum %s has no values", m.Name)
+				}
+
+				enums = append(enums, _Enum{
+					Name:   m.Name,
+					Values: m.Values,
 				})
 				continue
 			}
@@ -78,11 +121,17 @@ func loadModel(path string) ([]_Struct, []_Resource) {
 				}
 				pkey = makePropCallerString(pkey)
 
+				indexes, err := nameIndexes(m.Name, m.Indexes)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+
 				resources = append(resources, _Resource{
-					Name:   m.Name,
-					Spec:   m.Spec,
-					Status: m.Status,
-					Pkey:   pkey,
+					Name:    m.Name,
+					Spec:    m.Spec,
+					Status:  m.Status,
+					Pkey:    pkey,
+					Indexes: indexes,
 					// ApiMethods: m.ApiMethods,
 				})
 				continue
@@ -90,7 +139,293 @@ func loadModel(path string) ([]_Struct, []_Resource) {
 		}
 	}
 
-	return structs, resources
+	err := checkEmbeds(structs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	err = checkOptionals(structs, enums)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	resources = uniqueIndexes(structs, resources)
+	resources = optionalPaths(structs, resources)
+	resources, err = indexTypes(structs, enums, resources)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return structs, resources, enums, nil
+}
+
+// checkEmbeds rejects unknown and cyclic embeds and properties
+// declared more than once in a structure and the ones it embeds
+func checkEmbeds(structs []_Struct) error {
+	lookup := structLookup(structs)
+
+	for _, s := range structs {
+		_, err := embeddedProps(lookup, s.Name, []string{})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkOptionals rejects optional structures, only scalars can be left unset
+func checkOptionals(structs []_Struct, enums []_Enum) error {
+	for _, s := range structs {
+		for _, p := range markEnumProps(s.Props, enums) {
+			if p.IsOptional() && p.IsStructure() {
+				return fmt.Errorf("%s.%s of type %s cannot be optional",
+					s.Name, p.Json, p.StrippedType())
+			}
+		}
+	}
+
+	return nil
+}
+
+// embeddedProps lists the properties of a structure
+// together with the ones of the structures it embeds
+func embeddedProps(
+	lookup map[string]_Struct,
+	name string,
+	chain []string) ([]_Prop, error) {
+
+	chain = append(chain, name)
+	if slices.Contains(chain[:len(chain)-1], name) {
+		return nil, fmt.Errorf("embed cycle %s", strings.Join(chain, " -> "))
+	}
+
+	s, ok := lookup[name]
+	if !ok {
+		return nil, fmt.Errorf("%s embeds unknown structure %s",
+			chain[len(chain)-2], name)
+	}
+
+	res := []_Prop{}
+	for _, e := range s.Embeds {
+		props, err := embeddedProps(lookup, e, chain)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, props...)
+	}
+	res = append(res, s.Props...)
+
+	seen := map[string]bool{}
+	for _, p := range res {
+		if seen[p.Json] {
+			return nil, fmt.Errorf("%s.%s is declared more than once", name, p.Json)
+		}
+		seen[p.Json] = true
+	}
+
+	return res, nil
+}
+
+func isUnique(p _Prop) bool {
+	return p.Unique
+}
+
+func isOptional(p _Prop) bool {
+	return p.IsOptional()
+}
+
+// unique properties become unique indexes of every object kind
+// whose spec or status contains them
+func uniqueIndexes(structs []_Struct, resources []_Resource) []_Resource {
+	lookup := structLookup(structs)
+
+	res := []_Resource{}
+	for _, r := range resources {
+		for _, p := range resourcePaths(lookup, r, isUnique) {
+			r.Indexes = append(r.Indexes, _Index{
+				Name:   store.IndexName(r.Name, []string{p}),
+				Keys:   []string{p},
+				Unique: true,
+			})
+		}
+
+		res = append(res, r)
+	}
+
+	return res
+}
+
+func optionalPaths(structs []_Struct, resources []_Resource) []_Resource {
+	lookup := structLookup(structs)
+
+	res := []_Resource{}
+	for _, r := range resources {
+		r.Optionals = resourcePaths(lookup, r, isOptional)
+		res = append(res, r)
+	}
+
+	return res
+}
+
+// indexTypes rejects index keys not resolving to a property
+// and sets the types of the ones that do
+func indexTypes(
+	structs []_Struct,
+	enums []_Enum,
+	resources []_Resource) ([]_Resource, error) {
+
+	lookup := structLookup(structs)
+
+	res := []_Resource{}
+	for _, r := range resources {
+		indexes := []_Index{}
+		for _, i := range r.Indexes {
+			i.Types = []string{}
+			for _, k := range i.Keys {
+				tp, ok := keyType(lookup, enums, r, k)
+				if !ok {
+					return nil, fmt.Errorf(
+						"index %s has key %s which is not a property of %s",
+						i.Name, k, r.Name)
+				}
+				i.Types = append(i.Types, tp)
+			}
+			indexes = append(indexes, i)
+		}
+		r.Indexes = indexes
+		res = append(res, r)
+	}
+
+	return res, nil
+}
+
+// keyType is the JSON type of the values of a property path,
+// empty when it does not hold scalars, and tells if the path
+// resolves to a property through the structures it nests
+func keyType(
+	lookup map[string]_Struct,
+	enums []_Enum,
+	r _Resource,
+	key string) (string, bool) {
+
+	tokens := strings.Split(key, ".")
+	name := ""
+	switch tokens[0] {
+	case "metadata":
+		switch key {
+		case "metadata.revision":
+			return "number", true
+		case "metadata.kind", "metadata.identity",
+			"metadata.created", "metadata.updated":
+			return "string", true
+		}
+		return "", false
+	case "spec":
+		name = r.Spec
+	case "status":
+		name = r.Status
+	}
+
+	for i, t := range tokens[1:] {
+		if _, ok := lookup[name]; !ok {
+			return "", false
+		}
+
+		props, err := embeddedProps(lookup, name, []string{})
+		if err != nil {
+			return "", false
+		}
+
+		idx := slices.IndexFunc(props, func(p _Prop) bool { return p.Json == t })
+		if idx < 0 {
+			return "", false
+		}
+
+		p := props[idx]
+		if i < len(tokens)-2 {
+			if p.IsArray() || p.IsMap() {
+				return "", false
+			}
+			name = p.Type
+			continue
+		}
+
+		tp := p.StrippedType()
+		if p.IsArray() || p.IsMap() {
+			return "", true
+		}
+		switch tp {
+		case "string", "store.Time":
+			return "string", true
+		case "int", "float", "float64", "time.Duration":
+			return "number", true
+		case "bool":
+			return "bool", true
+		}
+		for _, e := range enums {
+			if e.Name == tp {
+				return "string", true
+			}
+		}
+
+		return "", true
+	}
+
+	return "", false
+}
+
+func structLookup(structs []_Struct) map[string]_Struct {
+	lookup := make(map[string]_Struct)
+	for _, s := range structs {
+		lookup[s.Name] = s
+	}
+
+	return lookup
+}
+
+func resourcePaths(
+	lookup map[string]_Struct,
+	r _Resource,
+	match func(_Prop) bool) []string {
+
+	res := propPaths(lookup, r.Spec, "spec", map[string]bool{}, match)
+	return append(res,
+		propPaths(lookup, r.Status, "status", map[string]bool{}, match)...)
+}
+
+// propPaths lists the paths of the matching properties
+// of a structure and of the structures nested in it
+func propPaths(
+	lookup map[string]_Struct,
+	name string,
+	prefix string,
+	visited map[string]bool,
+	match func(_Prop) bool) []string {
+
+	_, ok := lookup[name]
+	if !ok || visited[name] {
+		return nil
+	}
+	props, err := embeddedProps(lookup, name, []string{})
+	if err != nil {
+		return nil
+	}
+	visited[name] = true
+	defer delete(visited, name)
+
+	res := []string{}
+	for _, p := range props {
+		path := fmt.Sprintf("%s.%s", prefix, p.Json)
+		if match(p) {
+			res = append(res, path)
+		}
+		if !p.IsArray() && !p.IsMap() {
+			res = append(res, propPaths(lookup, p.Type, path, visited, match)...)
+		}
+	}
+
+	return res
 }
 
 func readModel(path string) (*_Model, error) {
@@ -109,17 +444,60 @@ func readModel(path string) (*_Model, error) {
 func capitalizeProps(l []_Prop) []_Prop {
 	res := []_Prop{}
 	for _, p := range l {
-		res = append(res,
-			_Prop{
-				Name:    capitalize(p.Name),
-				Json:    decapitalize(p.Name),
-				Type:    p.Type,
-				Default: p.Default,
-			})
+		p.Json = decapitalize(p.Name)
+		p.Name = capitalize(p.Name)
+		p.Type = modelType(p.Type, p.Optional)
+		res = append(res, p)
 	}
 	return res
 }
 
+// modelType maps the model type names to Go types
+func modelType(tp string, optional bool) string {
+	if optional && !strings.HasPrefix(tp, "*") {
+		tp = "*" + tp
+	}
+
+	switch {
+	case strings.HasPrefix(tp, "*"):
+		return "*" + modelType(tp[1:], false)
+	case strings.HasPrefix(tp, "[]"):
+		return "[]" + modelType(tp[2:], false)
+	case strings.HasPrefix(tp, "map["):
+		i := strings.Index(tp, "]")
+		return tp[:i+1] + modelType(tp[i+1:], false)
+	}
+
+	switch tp {
+	case "time":
+		return "store.Time"
+	case "duration":
+		return "time.Duration"
+	case "bytes":
+		return "[]byte"
+	}
+
+	return tp
+}
+
+func nameIndexes(kind string, l []_Index) ([]_Index, error) {
+	res := []_Index{}
+	for _, i := range l {
+		if len(i.Keys) == 0 {
+			return nil, fmt.Errorf("index of %s has no keys", kind)
+		}
+		if len(i.Name) == 0 {
+			i.Name = store.IndexName(kind, i.Keys)
+		}
+		err := store.ValidateIndexName(i.Name)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, i)
+	}
+	return res, nil
+}
+
 func makePropCallerString(pkey string) string {
 	tok := strings.Split(pkey, ".")
 	cap := []string{}
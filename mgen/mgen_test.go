@@ -2,10 +2,18 @@ package mgen_test
 
 import (
 	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/wazofski/storz/generated"
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/mgen"
+	"github.com/wazofski/storz/store"
 )
 
 var _ = Describe("mgen", func() {
@@ -85,6 +93,431 @@ var _ = Describe("mgen", func() {
 		Expect(anotherWorld).ToNot(BeNil())
 	})
 
+	It("has indexes", func() {
+		schema := generated.Schema()
+		indexes := store.Indexes(schema, generated.WorldKind())
+		Expect(len(indexes)).To(Equal(2))
+
+		Expect(indexes[0].Name).To(Equal("idx_world_spec_nested_counter"))
+		Expect(indexes[0].Keys).To(Equal([]string{"spec.nested.counter"}))
+		Expect(indexes[0].Unique).To(BeFalse())
+
+		Expect(indexes[1].Name).To(Equal("idx_world_alive_counter"))
+		Expect(indexes[1].Keys).To(Equal(
+			[]string{"spec.nested.alive", "spec.nested.counter"}))
+		Expect(indexes[1].Types).To(Equal([]string{"bool", "number"}))
+
+		citizen := store.Indexes(schema, generated.CitizenKind())
+		Expect(citizen[0].Types).To(Equal([]string{"string"}))
+
+		Expect(store.Indexes(schema, "world")).To(Equal(indexes))
+		Expect(store.Indexes(schema, generated.SecondWorldKind())).To(BeNil())
+	})
+
+	It("can validate objects", func() {
+		citizen := generated.CitizenFactory()
+		err := store.Validate(citizen)
+		Expect(errors.Is(err, constants.ErrInvalidObject)).To(BeTrue())
+		Expect(err.Error()).To(Equal("invalid object: spec.name is required"))
+
+		citizen.Spec().SetName("abc")
+		Expect(store.Validate(citizen)).To(BeNil())
+
+		email := "abc@storz"
+		citizen.Spec().SetEmail(&email)
+		citizen.Spec().SetAge(150)
+		citizen.Spec().SetRole("citizen")
+		Expect(store.Validate(citizen)).To(BeNil())
+
+		citizen.Spec().SetName(strings.Repeat("a", 33))
+		Expect(store.Validate(citizen).Error()).To(
+			Equal("invalid object: spec.name is longer than 32"))
+
+		citizen.Spec().SetName("abc")
+		citizen.Spec().SetAge(151)
+		Expect(store.Validate(citizen).Error()).To(
+			Equal("invalid object: spec.age is greater than 150"))
+
+		// zero values are set values
+		citizen.Spec().SetAge(0)
+		citizen.Spec().SetResident(false)
+		Expect(store.Validate(citizen)).To(BeNil())
+
+		citizen.Spec().Address().SetNumber(0)
+		Expect(store.Validate(citizen).Error()).To(
+			Equal("invalid object: spec.address.number is less than 1"))
+
+		Expect(store.Validate(generated.WorldFactory())).To(BeNil())
+	})
+
+	It("can factory default values", func() {
+		citizen := generated.CitizenFactory()
+		Expect(citizen.Spec().Role()).To(Equal(generated.RoleCitizen))
+		Expect(citizen.Spec().Nicknames()).To(Equal([]string{"anonymous"}))
+		Expect(citizen.Spec().Address().Street()).To(Equal("main"))
+		Expect(citizen.Spec().Address().Number()).To(Equal(1))
+		Expect(len(citizen.Spec().Previous())).To(Equal(1))
+		Expect(citizen.Spec().Previous()[0].Street()).To(Equal("first"))
+		Expect(citizen.Spec().Previous()[0].Number()).To(Equal(1))
+		Expect(citizen.Spec().Settings()).To(Equal(map[string]string{"lang": "en"}))
+
+		Expect(generated.AddressFactory().Street()).To(Equal(""))
+	})
+
+	It("can unmarshal default values of missing props", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetRole("mayor")
+		citizen.Spec().SetNicknames([]string{})
+
+		err := json.Unmarshal(
+			[]byte(`{"spec":{"name":"abc","address":{"street":"second"},"settings":null}}`),
+			&citizen)
+		Expect(err).To(BeNil())
+
+		Expect(citizen.Spec().Name()).To(Equal("abc"))
+		Expect(citizen.Spec().Role()).To(Equal(generated.RoleCitizen))
+		Expect(citizen.Spec().Nicknames()).To(Equal([]string{"anonymous"}))
+		Expect(citizen.Spec().Address().Street()).To(Equal("second"))
+		Expect(citizen.Spec().Address().Number()).To(Equal(1))
+		Expect(citizen.Spec().Settings()).To(Equal(map[string]string{"lang": "en"}))
+	})
+
+	It("cannot unmarshal missing required props", func() {
+		citizen := generated.CitizenFactory()
+
+		err := json.Unmarshal([]byte(`{"spec":{"email":"abc@storz"}}`), &citizen)
+		Expect(errors.Is(err, constants.ErrInvalidObject)).To(BeTrue())
+		Expect(err.Error()).To(Equal("invalid object: spec.name is required"))
+
+		err = json.Unmarshal(
+			[]byte(`{"spec":{"name":"abc","previous":[{"number":2}]}}`),
+			&citizen)
+		Expect(err.Error()).To(Equal("invalid object: spec.previous.0.street is required"))
+	})
+
+	It("has enums", func() {
+		Expect(generated.RoleFactory()).To(Equal(generated.RoleCitizen))
+		Expect(generated.RoleMayor.Values()).To(Equal(
+			[]string{"citizen", "mayor", "city-council"}))
+		Expect(string(generated.RoleCityCouncil)).To(Equal("city-council"))
+
+		enums := store.Enums(generated.Schema())
+		Expect(len(enums)).To(Equal(1))
+		Expect(enums["Role"].Values()).To(Equal(generated.RoleMayor.Values()))
+	})
+
+	It("can marshal known enum values only", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("abc")
+		citizen.Spec().SetRole(generated.RoleMayor)
+
+		data, err := json.Marshal(citizen)
+		Expect(err).To(BeNil())
+
+		another := generated.CitizenFactory()
+		err = json.Unmarshal(data, &another)
+		Expect(err).To(BeNil())
+		Expect(another.Spec().Role()).To(Equal(generated.RoleMayor))
+
+		citizen.Spec().SetRole("king")
+		data, err = json.Marshal(citizen)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(ContainSubstring(`"role":"king"`))
+		Expect(errors.Is(store.Validate(citizen), constants.ErrInvalidObject)).To(BeTrue())
+
+		err = json.Unmarshal([]byte(`{"spec":{"name":"abc","role":"king"}}`), &another)
+		Expect(err.Error()).To(Equal(
+			"invalid object: spec.role is not one of [citizen mayor city-council]"))
+	})
+
+	It("can generate optional enums", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("abc")
+		Expect(citizen.Spec().FormerRole()).To(BeNil())
+		Expect(store.Validate(citizen)).To(BeNil())
+
+		role := generated.RoleMayor
+		citizen.Spec().SetFormerRole(&role)
+		data, err := json.Marshal(citizen.Spec())
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(ContainSubstring(`"formerRole":"mayor"`))
+
+		another := generated.CitizenSpecFactory()
+		err = json.Unmarshal(data, another)
+		Expect(err).To(BeNil())
+		Expect(*another.FormerRole()).To(Equal(generated.RoleMayor))
+
+		role = generated.Role("king")
+		Expect(store.Validate(citizen).Error()).To(HavePrefix(
+			"invalid object: spec.formerRole is not one of"))
+	})
+
+	It("can factory optional, time, duration and bytes props", func() {
+		spec := generated.CitizenFactory().Spec()
+		Expect(spec.Title()).To(BeNil())
+		Expect(spec.Score()).To(BeNil())
+		Expect(spec.Born().IsZero()).To(BeTrue())
+		Expect(spec.Lease()).To(Equal(90 * time.Minute))
+		Expect(spec.Avatar()).To(Equal([]byte("storz")))
+
+		schema := generated.Schema()
+		Expect(store.Optionals(schema, generated.CitizenKind())).To(Equal(
+			[]string{"spec.email", "spec.formerRole", "spec.title", "spec.score"}))
+	})
+
+	It("can marshal optional, time, duration and bytes props", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("abc")
+
+		data, err := json.Marshal(citizen.Spec())
+		Expect(err).To(BeNil())
+		Expect(string(data)).ToNot(ContainSubstring("title"))
+		Expect(string(data)).ToNot(ContainSubstring("score"))
+		Expect(string(data)).To(ContainSubstring(`"lease":5400000000000`))
+		Expect(string(data)).To(ContainSubstring(`"avatar":"c3Rvcno="`))
+
+		title := "mayor"
+		score := 0
+		at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.FixedZone("", 3600))
+		citizen.Spec().SetTitle(&title)
+		citizen.Spec().SetScore(&score)
+		citizen.Spec().SetBorn(store.TimeOf(at))
+		citizen.Spec().SetVisits([]store.Time{store.TimeOf(at)})
+
+		data, err = json.Marshal(citizen.Spec())
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(ContainSubstring(`"title":"mayor"`))
+		Expect(string(data)).To(ContainSubstring(`"score":0`))
+		Expect(string(data)).To(ContainSubstring(`"born":"2024-01-01T11:00:00.000000000Z"`))
+
+		another := generated.CitizenFactory()
+		err = json.Unmarshal(data, another.Spec())
+		Expect(err).To(BeNil())
+		Expect(*another.Spec().Title()).To(Equal("mayor"))
+		Expect(*another.Spec().Score()).To(Equal(0))
+		Expect(another.Spec().Born().Equal(at)).To(BeTrue())
+		Expect(another.Spec().Visits()[0].Equal(at)).To(BeTrue())
+	})
+
+	It("can validate optional props when set", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("abc")
+		Expect(store.Validate(citizen)).To(BeNil())
+
+		score := -1
+		citizen.Spec().SetScore(&score)
+		Expect(store.Validate(citizen).Error()).To(
+			Equal("invalid object: spec.score is less than 0"))
+
+		title := strings.Repeat("a", 17)
+		score = 0
+		citizen.Spec().SetTitle(&title)
+		Expect(store.Validate(citizen).Error()).To(
+			Equal("invalid object: spec.title is longer than 16"))
+	})
+
+	It("can clone optional, time, duration and bytes props", func() {
+		title := "mayor"
+		at := time.Now()
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("abc")
+		citizen.Spec().SetTitle(&title)
+		citizen.Spec().SetBorn(store.TimeOf(at))
+		citizen.Spec().SetLease(time.Hour)
+
+		clone := citizen.Clone().(generated.Citizen)
+		Expect(clone.Spec().Born()).To(Equal(citizen.Spec().Born()))
+		Expect(clone.Spec().Lease()).To(Equal(time.Hour))
+		Expect(clone.Spec().Score()).To(BeNil())
+		Expect(*clone.Spec().Title()).To(Equal("mayor"))
+
+		*clone.Spec().Title() = "citizen"
+		clone.Spec().Avatar()[0] = 'S'
+		Expect(title).To(Equal("mayor"))
+		Expect(citizen.Spec().Avatar()).To(Equal([]byte("storz")))
+	})
+
+	It("can call setters and getters of embedded structures", func() {
+		citizen := generated.CitizenFactory()
+		Expect(citizen.Spec().Owner()).To(Equal("nobody"))
+		Expect(citizen.Spec().Description()).To(Equal(""))
+
+		var described generated.Described = citizen.Spec()
+		described.SetOwner("mayor")
+		described.SetDescription("abc")
+		described.SetTags([]string{"a", "b"})
+
+		Expect(citizen.Spec().Owner()).To(Equal("mayor"))
+		Expect(citizen.Spec().Description()).To(Equal("abc"))
+		Expect(citizen.Spec().Tags()).To(Equal([]string{"a", "b"}))
+	})
+
+	It("can marshal embedded structures", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("abc")
+		citizen.Spec().SetDescription("qwe")
+
+		data, err := json.Marshal(citizen.Spec())
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(ContainSubstring(`"owner":"nobody"`))
+		Expect(string(data)).To(ContainSubstring(`"description":"qwe"`))
+
+		another := generated.CitizenSpecFactory()
+		err = json.Unmarshal([]byte(`{"name":"abc","tags":["a"]}`), another)
+		Expect(err).To(BeNil())
+		Expect(another.Owner()).To(Equal("nobody"))
+		Expect(another.Tags()).To(Equal([]string{"a"}))
+
+		err = json.Unmarshal([]byte(`{"name":"abc","owner":1}`), another)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("can validate and clone embedded structures", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("abc")
+		citizen.Spec().SetTags([]string{"a"})
+
+		clone := citizen.Clone().(generated.Citizen)
+		Expect(clone.Spec().Owner()).To(Equal("nobody"))
+		clone.Spec().Tags()[0] = "b"
+		Expect(citizen.Spec().Tags()).To(Equal([]string{"a"}))
+
+		citizen.Spec().SetOwner(strings.Repeat("a", 17))
+		Expect(store.Validate(citizen).Error()).To(
+			Equal("invalid object: spec.owner is longer than 16"))
+	})
+
+	It("cannot generate models with embed cycles", func() {
+		model := GinkgoT().TempDir()
+		err := os.WriteFile(filepath.Join(model, "model.yaml"), []byte(`
+types:
+  - kind: Struct
+    name: A
+    embeds: [B]
+  - kind: Struct
+    name: B
+    embeds: [A]
+`), 0644)
+		Expect(err).To(BeNil())
+
+		err = mgen.Generate(model)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(Equal("embed cycle A -> B -> A"))
+	})
+
+	It("cannot generate models with invalid index names", func() {
+		model := GinkgoT().TempDir()
+		err := os.WriteFile(filepath.Join(model, "model.yaml"), []byte(`
+types:
+  - kind: Object
+    name: A
+    indexes:
+      - name: "idx; DROP TABLE Objects"
+        keys: [metadata.created]
+`), 0644)
+		Expect(err).To(BeNil())
+
+		err = mgen.Generate(model)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(HavePrefix("invalid index name"))
+	})
+
+	It("cannot generate models with unknown index keys", func() {
+		keys := map[string]string{
+			"spec.missing":     "index idx_a_spec_missing has key spec.missing which is not a property of A",
+			"spec.b.missing":   "index idx_a_spec_b_missing has key spec.b.missing which is not a property of A",
+			"spec.list.name":   "index idx_a_spec_list_name has key spec.list.name which is not a property of A",
+			"spec.name.length": "index idx_a_spec_name_length has key spec.name.length which is not a property of A",
+			"status.name":      "index idx_a_status_name has key status.name which is not a property of A",
+			"metadata.missing": "index idx_a_metadata_missing has key metadata.missing which is not a property of A",
+			"missing":          "index idx_a_missing has key missing which is not a property of A",
+		}
+
+		for key, message := range keys {
+			model := GinkgoT().TempDir()
+			err := os.WriteFile(filepath.Join(model, "model.yaml"), []byte(`
+types:
+  - kind: Object
+    name: A
+    spec: ASpec
+    indexes:
+      - keys: [`+key+`]
+  - kind: Struct
+    name: ASpec
+    properties:
+      - name: name
+        type: string
+      - name: b
+        type: B
+      - name: list
+        type: "[]B"
+  - kind: Struct
+    name: B
+    properties:
+      - name: name
+        type: string
+`), 0644)
+			Expect(err).To(BeNil())
+
+			err = mgen.Generate(model)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(Equal(message))
+		}
+	})
+
+	It("cannot generate models with optional structures", func() {
+		model := GinkgoT().TempDir()
+		err := os.WriteFile(filepath.Join(model, "model.yaml"), []byte(`
+types:
+  - kind: Struct
+    name: A
+    properties:
+      - name: b
+        type: B
+        optional: true
+  - kind: Struct
+    name: B
+`), 0644)
+		Expect(err).To(BeNil())
+
+		err = mgen.Generate(model)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(Equal("A.b of type B cannot be optional"))
+	})
+
+	It("cannot generate models with invalid defaults", func() {
+		defaults := map[string]string{
+			"type: int\n        default: abc":                           "A.p has an invalid default: abc is not of type int",
+			"type: int\n        min: 1\n        default: 0":             "A.p has an invalid default: 0 is less than 1",
+			"type: int\n        max: 1\n        default: 2":             "A.p has an invalid default: 2 is greater than 1",
+			"type: string\n        maxLength: 1\n        default: ab":   "A.p has an invalid default: ab is longer than 1",
+			"type: string\n        pattern: \"^a\"\n        default: b": "A.p has an invalid default: b does not match ^a",
+			"type: string\n        enum: [a]\n        default: b":       "A.p has an invalid default: b is not one of [a]",
+			"type: E\n        default: b":                               "A.p has an invalid default: b is not of type E",
+			"type: \"[]E\"\n        default: [a, b]":                    "A.p has an invalid default: b is not of type E",
+		}
+
+		for prop, message := range defaults {
+			model := GinkgoT().TempDir()
+			err := os.WriteFile(filepath.Join(model, "model.yaml"), []byte(`
+types:
+  - kind: Enum
+    name: E
+    values: [a]
+  - kind: Struct
+    name: A
+    properties:
+      - name: p
+        `+prop+`
+`), 0644)
+			Expect(err).To(BeNil())
+
+			err = mgen.Generate(model)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(Equal(message))
+		}
+	})
+
 	It("can clone objects", func() {
 		world := generated.WorldFactory()
 		world.Spec().Nested().SetCounter(10)
@@ -0,0 +1,73 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/wazofski/storz/store"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Batch runs the ops in a session transaction
+// which requires the server to be a replica set or a sharded cluster
+func (d *mongoStore) Batch(
+	ctx context.Context,
+	ops []store.Op) (store.ObjectList, error) {
+
+	log.Printf("batch %d", len(ops))
+
+	for _, op := range ops {
+		err := op.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := d.TestConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := d.Client.StartSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(ctx)
+
+	ret, err := session.WithTransaction(ctx,
+		func(sctx mongo.SessionContext) (interface{}, error) {
+			return d.apply(sctx, ops)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return ret.(store.ObjectList), nil
+}
+
+func (d *mongoStore) apply(
+	ctx context.Context,
+	ops []store.Op) (store.ObjectList, error) {
+
+	res := store.ObjectList{}
+	for _, op := range ops {
+		var ret store.Object
+		var err error
+
+		switch op.Type {
+		case store.OpCreate:
+			ret, err = d.Create(ctx, op.Object)
+		case store.OpUpdate:
+			ret, err = d.Update(ctx, op.Identity, op.Object, op.UpdateOptions()...)
+		case store.OpDelete:
+			err = d.Delete(ctx, op.Identity, op.DeleteOptions()...)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, ret)
+	}
+
+	return res, nil
+}
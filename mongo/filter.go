@@ -0,0 +1,151 @@
+package mongo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wazofski/storz/filter"
+	"github.com/wazofski/storz/store"
+	"github.com/wazofski/storz/store/options"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mopt "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var mongoOperators = map[filter.Operator]string{
+	filter.OpEq: "$eq",
+	filter.OpNe: "$ne",
+	filter.OpLt: "$lt",
+	filter.OpLe: "$lte",
+	filter.OpGt: "$gt",
+	filter.OpGe: "$gte",
+	filter.OpIn: "$in",
+}
+
+func compileFilter(expr *filter.Expression) bson.M {
+	switch expr.Op {
+	case filter.OpAnd, filter.OpOr:
+		a := bson.A{}
+		for _, x := range expr.Exprs {
+			a = append(a, compileFilter(x))
+		}
+
+		if expr.Op == filter.OpOr {
+			return bson.M{"$or": a}
+		}
+		return bson.M{"$and": a}
+	case filter.OpNot:
+		return bson.M{"$nor": bson.A{compileFilter(expr.Exprs[0])}}
+	}
+
+	return bson.M{
+		fmt.Sprintf("object.%s", expr.Key): bson.M{
+			mongoOperators[expr.Op]: expr.Value,
+		},
+	}
+}
+
+func compileContinue(token *store.ContinueToken) bson.M {
+	clauses := bson.A{}
+
+	equal := bson.A{}
+	for i, o := range token.Order {
+		path := fmt.Sprintf("object.%s", o.Key)
+		val := token.Values[i]
+
+		var after bson.M = nil
+		switch {
+		case val == nil && !o.Descending:
+			after = bson.M{path: bson.M{"$ne": nil}}
+		case val == nil:
+		case o.Descending:
+			after = bson.M{"$or": bson.A{
+				bson.M{path: bson.M{"$lt": val}},
+				bson.M{path: nil},
+			}}
+		default:
+			after = bson.M{path: bson.M{"$gt": val}}
+		}
+
+		if after != nil {
+			clauses = append(clauses,
+				bson.M{"$and": append(append(bson.A{}, equal...), after)})
+		}
+
+		equal = append(equal, bson.M{path: val})
+	}
+
+	pkey := bson.M{"pkey": bson.M{"$gt": token.Key}}
+	if token.KeyDescending() {
+		pkey = bson.M{"pkey": bson.M{"$lt": token.Key}}
+	}
+
+	clauses = append(clauses, bson.M{"$and": append(equal, pkey)})
+
+	return bson.M{"$or": clauses}
+}
+
+func compileOrder(copt options.CommonOptionHolder) bson.D {
+	res := bson.D{}
+	direction := 1
+	if copt.Descending {
+		direction = -1
+	}
+	for _, o := range copt.Order {
+		direction = 1
+		if o.Descending {
+			direction = -1
+		}
+		res = append(res, bson.E{Key: fmt.Sprintf("object.%s", o.Key), Value: direction})
+	}
+
+	return append(res, bson.E{Key: "pkey", Value: direction})
+}
+
+// the order values and the primary key are kept along with the fields
+// so the continue token can be taken from trimmed documents,
+// nested paths are left out since mongo rejects overlapping ones
+func compileFields(fields []string, order []options.OrderSetting) bson.M {
+	paths := append([]string{}, fields...)
+	for _, o := range order {
+		paths = append(paths, o.Key)
+	}
+
+	res := bson.M{"_id": 0, "pkey": 1}
+	for _, p := range paths {
+		nested := false
+		for _, q := range paths {
+			if strings.HasPrefix(p, q+".") {
+				nested = true
+			}
+		}
+		if !nested {
+			res[fmt.Sprintf("object.%s", p)] = 1
+		}
+	}
+
+	return res
+}
+
+// indexes are limited to the objects of the kind, unique ones
+// to the objects having all the keys so that missing values do not collide
+func compileIndex(kind string, index store.Index) mongo.IndexModel {
+	typ := strings.ToLower(kind)
+	keys := bson.D{{Key: "type", Value: 1}}
+	partial := bson.M{"type": typ}
+	for _, k := range index.Keys {
+		path := fmt.Sprintf("object.%s", k)
+		keys = append(keys, bson.E{Key: path, Value: 1})
+		if index.Unique {
+			partial[path] = bson.M{"$exists": true}
+		}
+	}
+
+	return mongo.IndexModel{
+		Keys: keys,
+		Options: mopt.Index().
+			SetName(index.Name).
+			SetUnique(index.Unique).
+			SetPartialFilterExpression(partial),
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/wazofski/storz/filter"
 	"github.com/wazofski/storz/internal/constants"
 	"github.com/wazofski/storz/internal/logger"
 	"github.com/wazofski/storz/store"
@@ -94,6 +95,22 @@ func (d *mongoStore) prepare() error {
 		collection.Indexes().CreateOne(context.Background(), i)
 	}
 
+	for _, kind := range d.Schema.Types() {
+		for _, i := range store.Indexes(d.Schema, kind) {
+			err := utils.ValidateFields(d.Schema, kind, i.Keys)
+			if err != nil {
+				return fmt.Errorf("index %s: %w", i.Name, err)
+			}
+
+			_, err = collection.Indexes().CreateOne(
+				context.Background(),
+				compileIndex(kind, i))
+			if err != nil {
+				return fmt.Errorf("index %s: %w", i.Name, err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -148,23 +165,20 @@ func (d *mongoStore) Create(
 		return nil, err
 	}
 
-	typ := strings.ToLower(obj.Metadata().Kind())
-
-	collection := d.Client.Database(d.DB).Collection(collectionName)
-	_, err = collection.InsertOne(ctx,
-		_Record{
-			IdPath: obj.Metadata().Identity().Path(),
-			PkPath: fmt.Sprintf("%s/%s", typ, obj.PrimaryKey()),
-			Pkey:   obj.PrimaryKey(),
-			Type:   typ,
-			Obj:    toBSON(obj),
-		})
+	clone, err := utils.CopyObject(obj, d.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if clone.Metadata().Revision() < 1 {
+		clone.Metadata().(store.MetaSetter).SetRevision(1)
+	}
 
+	err = d.insert(ctx, clone)
 	if err != nil {
 		return nil, err
 	}
 
-	return obj.Clone(), nil
+	return clone.Clone(), nil
 }
 
 func (d *mongoStore) Update(
@@ -188,17 +202,34 @@ func (d *mongoStore) Update(
 		return nil, constants.ErrObjectNil
 	}
 
+	existing, _ := d.Get(ctx, identity)
+	if existing == nil {
+		return nil, constants.ErrNoSuchObject
+	}
+
+	if copt.IfRevision > 0 && copt.IfRevision != existing.Metadata().Revision() {
+		return nil, constants.ErrConflict
+	}
+
 	err = d.TestConnection()
 	if err != nil {
 		return nil, err
 	}
 
-	err = d.Delete(ctx, identity)
+	clone, err := utils.CopyObject(obj, d.Schema)
 	if err != nil {
 		return nil, err
 	}
+	clone.Metadata().(store.MetaSetter).SetRevision(
+		existing.Metadata().Revision() + 1)
 
-	return d.Create(ctx, obj)
+	// fails when a concurrent update got there first
+	err = d.replace(ctx, existing, clone)
+	if err != nil {
+		return nil, err
+	}
+
+	return clone.Clone(), nil
 }
 
 func (d *mongoStore) Delete(
@@ -222,27 +253,16 @@ func (d *mongoStore) Delete(
 		return constants.ErrNoSuchObject
 	}
 
-	err = d.TestConnection()
-	if err != nil {
-		return err
+	if copt.IfRevision > 0 && copt.IfRevision != existing.Metadata().Revision() {
+		return constants.ErrConflict
 	}
 
-	collection := d.Client.Database(d.DB).Collection(collectionName)
-	_, err = collection.DeleteOne(ctx,
-		bson.M{
-			"idpath": identity.Path(),
-		})
-
+	err = d.TestConnection()
 	if err != nil {
-		log.Printf(err.Error())
+		return err
 	}
 
-	_, err = collection.DeleteOne(ctx,
-		bson.M{
-			"pkpath": identity.Path(),
-		})
-
-	return err
+	return d.remove(ctx, existing)
 }
 
 func (d *mongoStore) Get(
@@ -273,20 +293,30 @@ func (d *mongoStore) Get(
 			"idpath": identity.Path(),
 		}).Decode(&res)
 
-	if res != nil {
-		return fromBSON(res, d.Schema)
+	if res == nil {
+		collection.FindOne(ctx,
+			bson.M{
+				"pkpath": identity.Path(),
+			}).Decode(&res)
 	}
 
-	collection.FindOne(ctx,
-		bson.M{
-			"pkpath": identity.Path(),
-		}).Decode(&res)
+	if res == nil {
+		return nil, constants.ErrNoSuchObject
+	}
 
-	if res != nil {
-		return fromBSON(res, d.Schema)
+	ret, err := fromBSON(res, d.Schema)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, constants.ErrNoSuchObject
+	if copt.Fields != nil {
+		err = utils.ValidateFields(d.Schema, ret.Metadata().Kind(), copt.Fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return utils.ProjectObject(ret, d.Schema, copt.Fields)
 }
 
 func (d *mongoStore) List(
@@ -294,10 +324,23 @@ func (d *mongoStore) List(
 	identity store.ObjectIdentity,
 	opt ...options.ListOption) (store.ObjectList, error) {
 
+	page, err := d.ListPage(ctx, identity, opt...)
+	if err != nil {
+		return nil, err
+	}
+
+	return page.Objects, nil
+}
+
+func (d *mongoStore) ListPage(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (store.Page, error) {
+
 	log.Printf("list %s", identity)
 
 	if len(identity.Key()) > 0 {
-		return nil, constants.ErrInvalidPath
+		return store.Page{}, constants.ErrInvalidPath
 	}
 
 	var err error
@@ -305,31 +348,138 @@ func (d *mongoStore) List(
 	for _, o := range opt {
 		err = o.ApplyFunction()(&copt)
 		if err != nil {
-			return nil, err
+			return store.Page{}, err
 		}
 	}
 
+	token, err := store.ContinueFrom(copt)
+	if err != nil {
+		return store.Page{}, err
+	}
+
 	err = d.TestConnection()
 	if err != nil {
-		return nil, err
+		return store.Page{}, err
 	}
 
 	collection := d.Client.Database(d.DB).Collection(collectionName)
-	filter := bson.M{
-		"type": identity.Type(),
+	filter, err := d.listFilter(identity, copt)
+	if err != nil {
+		return store.Page{}, err
+	}
+
+	// keyset pagination
+	if token != nil {
+		and, _ := filter["$and"].(bson.A)
+		filter["$and"] = append(and, compileContinue(token))
+	}
+
+	opts := mopt.Find().SetSort(compileOrder(copt))
+
+	if copt.PageSize > 0 {
+		opts = opts.SetLimit(int64(copt.PageSize))
+	}
+
+	if copt.PageOffset > 0 {
+		opts = opts.SetSkip(int64(copt.PageOffset))
+	}
+
+	if copt.Fields != nil {
+		opts = opts.SetProjection(compileFields(copt.Fields, copt.Order))
+	}
+
+	cur, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return store.Page{}, err
+	}
+	defer cur.Close(ctx)
+
+	var qres []bson.M
+	if err = cur.All(ctx, &qres); err != nil {
+		return store.Page{}, err
+	}
+
+	res := store.ObjectList{}
+	for _, r := range qres {
+		var obj store.Object
+		if copt.Fields != nil {
+			obj, err = fromProjectedBSON(r, d.Schema, identity.Type(), copt.Fields)
+		} else {
+			obj, err = fromBSON(r, d.Schema)
+		}
+		if err != nil {
+			log.Printf(err.Error())
+			continue
+		}
+		res = append(res, obj)
+	}
+
+	page := store.Page{
+		Objects: res,
+	}
+
+	if store.Full(len(res), copt) {
+		if copt.Fields != nil {
+			page.Continue = projectedToken(qres[len(qres)-1], copt)
+		} else {
+			page.Continue = store.NextToken(res[len(res)-1], copt)
+		}
+	}
+
+	return page, nil
+}
+
+func (d *mongoStore) Count(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (int, error) {
+
+	log.Printf("count %s", identity)
+
+	if len(identity.Key()) > 0 {
+		return 0, constants.ErrInvalidPath
 	}
 
-	opts := mopt.Find()
-	if len(copt.OrderBy) > 0 {
-		order := 1
-		if !copt.OrderIncremental {
-			order = -1
+	var err error
+	copt := options.CommonOptionHolderFactory()
+	for _, o := range opt {
+		err = o.ApplyFunction()(&copt)
+		if err != nil {
+			return 0, err
 		}
+	}
+
+	err = d.TestConnection()
+	if err != nil {
+		return 0, err
+	}
 
-		opts = opts.SetSort(
-			bson.D{{Key: fmt.Sprintf("object.%s", copt.OrderBy), Value: order}})
+	filter, err := d.listFilter(identity, copt)
+	if err != nil {
+		return 0, err
 	}
 
+	collection := d.Client.Database(d.DB).Collection(collectionName)
+	res, err := collection.CountDocuments(ctx, filter)
+
+	return int(res), err
+}
+
+// listFilter builds the filtering part of list queries
+func (d *mongoStore) listFilter(
+	identity store.ObjectIdentity,
+	copt options.CommonOptionHolder) (bson.M, error) {
+
+	err := utils.ValidateOptions(d.Schema, identity.Type(), copt)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{
+		"type": identity.Type(),
+	}
+	and := bson.A{}
+
 	// pkey filter
 	if copt.KeyFilter != nil {
 		a := bson.A{}
@@ -343,47 +493,105 @@ func (d *mongoStore) List(
 
 	// prop filter
 	if copt.PropFilter != nil {
-		obj := d.Schema.ObjectForKind(identity.Type())
-		if obj == nil {
-			return nil, constants.ErrNoSuchObject
-		}
-		if utils.ObjectPath(obj, copt.PropFilter.Key) == nil {
-			return nil, constants.ErrInvalidFilter
-		}
-
 		filter[fmt.Sprintf("object.%s", copt.PropFilter.Key)] = copt.PropFilter.Value
 	}
 
-	if copt.PageSize > 0 {
-		opts = opts.SetLimit(int64(copt.PageSize))
+	// filter expression
+	if copt.Filter != nil {
+		and = append(and, compileFilter(copt.Filter))
 	}
 
-	if copt.PageOffset > 0 {
-		opts = opts.SetSkip(int64(copt.PageOffset))
+	// label selector, keys are matched literally
+	// since they may contain dots or start with $, $getField needs MongoDB 5.0
+	for k, v := range copt.Labels {
+		and = append(and, bson.M{"$expr": bson.M{"$eq": bson.A{
+			bson.M{"$getField": bson.M{
+				"field": bson.M{"$literal": k},
+				"input": "$object.metadata.labels",
+			}},
+			v,
+		}}})
+	}
+
+	if len(and) > 0 {
+		filter["$and"] = and
+	}
+
+	return filter, nil
+}
+
+func (d *mongoStore) insert(ctx context.Context, obj store.Object) error {
+	collection := d.Client.Database(d.DB).Collection(collectionName)
+	_, err := collection.InsertOne(ctx, toRecord(obj))
+	if mongo.IsDuplicateKeyError(err) {
+		return constants.ErrUniqueViolation
+	}
+
+	return err
+}
+
+// replace swaps the stored revision of the object in a single write
+func (d *mongoStore) replace(
+	ctx context.Context,
+	existing store.Object,
+	obj store.Object) error {
+
+	collection := d.Client.Database(d.DB).Collection(collectionName)
+	res, err := collection.ReplaceOne(ctx, revisionFilter(existing), toRecord(obj))
+	if mongo.IsDuplicateKeyError(err) {
+		return constants.ErrUniqueViolation
 	}
 
-	cur, err := collection.Find(ctx, filter, opts)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer cur.Close(ctx)
 
-	var qres []bson.M
-	if err = cur.All(ctx, &qres); err != nil {
-		return nil, err
+	if res.MatchedCount == 0 {
+		return constants.ErrConflict
 	}
 
-	res := store.ObjectList{}
-	for _, r := range qres {
-		d, err := fromBSON(r, d.Schema)
-		if err != nil {
-			log.Printf(err.Error())
-			continue
-		}
-		res = append(res, d)
+	return nil
+}
+
+func (d *mongoStore) remove(ctx context.Context, existing store.Object) error {
+	collection := d.Client.Database(d.DB).Collection(collectionName)
+	res, err := collection.DeleteOne(ctx, revisionFilter(existing))
+
+	if err != nil {
+		return err
 	}
 
-	return res, nil
+	if res.DeletedCount == 0 {
+		return constants.ErrConflict
+	}
+
+	return nil
+}
+
+func toRecord(obj store.Object) _Record {
+	typ := strings.ToLower(obj.Metadata().Kind())
+
+	return _Record{
+		IdPath: obj.Metadata().Identity().Path(),
+		PkPath: fmt.Sprintf("%s/%s", typ, obj.PrimaryKey()),
+		Pkey:   obj.PrimaryKey(),
+		Type:   typ,
+		Obj:    toBSON(obj),
+	}
+}
+
+func revisionFilter(existing store.Object) bson.M {
+	typ := strings.ToLower(existing.Metadata().Kind())
+
+	var revision interface{} = existing.Metadata().Revision()
+	if existing.Metadata().Revision() == 0 {
+		revision = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	return bson.M{
+		"pkpath":                   fmt.Sprintf("%s/%s", typ, existing.PrimaryKey()),
+		"object.metadata.revision": revision,
+	}
 }
 
 func toBSON(obj store.Object) interface{} {
@@ -405,3 +613,38 @@ func fromBSON(m bson.M, schema store.SchemaHolder) (store.Object, error) {
 
 	return utils.UnmarshalObject(data, schema, utils.ObjeectKind(data))
 }
+
+// projectedToken is the continue token of a trimmed document
+func projectedToken(m bson.M, copt options.CommonOptionHolder) string {
+	data, _ := json.Marshal(m["object"])
+	doc := make(map[string]interface{})
+	json.Unmarshal(data, &doc)
+
+	values := []interface{}{}
+	for _, o := range copt.Order {
+		val, _ := filter.Lookup(doc, o.Key)
+		values = append(values, val)
+	}
+
+	return store.TokenOf(copt, values, fmt.Sprint(m["pkey"]))
+}
+
+func fromProjectedBSON(
+	m bson.M,
+	schema store.SchemaHolder,
+	kind string,
+	fields []string) (store.Object, error) {
+
+	data, err := json.Marshal(m["object"])
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(map[string]interface{})
+	err = json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.UnmarshalProjection(doc, schema, kind, fields)
+}
@@ -87,7 +87,7 @@ func (d *reactStore) Create(
 	}
 
 	d.Log.Printf("create %s", obj.PrimaryKey())
-	err := d.runCallback(obj, ActionCreate)
+	err := d.runCallback(obj, ActionCreate, d)
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +111,7 @@ func (d *reactStore) Update(
 		return nil, constants.ErrNoSuchObject
 	}
 
-	err := d.runCallback(existing, ActionUpdate)
+	err := d.runCallback(existing, ActionUpdate, d)
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +130,7 @@ func (d *reactStore) Delete(
 		return constants.ErrNoSuchObject
 	}
 
-	err := d.runCallback(existing, ActionDelete)
+	err := d.runCallback(existing, ActionDelete, d)
 	if err != nil {
 		return err
 	}
@@ -156,7 +156,65 @@ func (d *reactStore) List(
 	return d.Store.List(ctx, identity, opt...)
 }
 
-func (d *reactStore) runCallback(obj store.Object, action Action) error {
+func (d *reactStore) ListPage(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (store.Page, error) {
+
+	d.Log.Printf("list %s", identity.Type())
+	return store.ListPage(ctx, d.Store, identity, opt...)
+}
+
+func (d *reactStore) Count(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (int, error) {
+
+	d.Log.Printf("count %s", identity.Type())
+	return store.Count(ctx, d.Store, identity, opt...)
+}
+
+func (d *reactStore) Watch(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.WatchOption) (<-chan store.Event, error) {
+
+	d.Log.Printf("watch %s", identity.Path())
+	return store.Watch(ctx, d.Store, identity, opt...)
+}
+
+// callbacks run for every op before the batch is handed over,
+// their writes are staged ahead of the op so that they are applied
+// along with the batch or not at all
+func (d *reactStore) Batch(
+	ctx context.Context,
+	ops []store.Op) (store.ObjectList, error) {
+
+	d.Log.Printf("batch %d", len(ops))
+	staged := &stagedStore{reactStore: d}
+	indexes := []int{}
+	for _, op := range ops {
+		err := staged.stage(ctx, op)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, len(staged.Ops)-1)
+	}
+
+	ret, err := store.Batch(ctx, d.Store, staged.Ops)
+	if err != nil {
+		return nil, err
+	}
+
+	res := store.ObjectList{}
+	for _, i := range indexes {
+		res = append(res, ret[i])
+	}
+
+	return res, nil
+}
+
+func (d *reactStore) runCallback(obj store.Object, action Action, st store.Store) error {
 	_, ok := d.CallbackRegistry[obj.Metadata().Kind()]
 	if !ok {
 		return nil
@@ -167,5 +225,115 @@ func (d *reactStore) runCallback(obj store.Object, action Action) error {
 		return nil
 	}
 
-	return d.CallbackRegistry[obj.Metadata().Kind()][action](obj, d)
+	return d.CallbackRegistry[obj.Metadata().Kind()][action](obj, st)
+}
+
+// stagedStore is handed to the callbacks of a batch, it collects
+// their writes as ops while reads see the store as it was before the batch
+type stagedStore struct {
+	*reactStore
+	Ops []store.Op
+}
+
+func (d *stagedStore) stage(ctx context.Context, op store.Op) error {
+	err := op.Validate()
+	if err != nil {
+		return err
+	}
+
+	if op.Type == store.OpCreate {
+		err = d.runCallback(op.Object, ActionCreate, d)
+	} else {
+		existing, _ := d.Get(ctx, op.Identity)
+		if existing == nil {
+			return constants.ErrNoSuchObject
+		}
+
+		action := ActionUpdate
+		if op.Type == store.OpDelete {
+			action = ActionDelete
+		}
+
+		err = d.runCallback(existing, action, d)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	d.Ops = append(d.Ops, op)
+	return nil
+}
+
+func (d *stagedStore) Create(
+	ctx context.Context,
+	obj store.Object,
+	opt ...options.CreateOption) (store.Object, error) {
+
+	err := d.stage(ctx, store.CreateOp(obj))
+	if err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+func (d *stagedStore) Update(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	obj store.Object,
+	opt ...options.UpdateOption) (store.Object, error) {
+
+	copt := options.CommonOptionHolderFactory()
+	for _, o := range opt {
+		err := o.ApplyFunction()(&copt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	op := store.UpdateOp(identity, obj)
+	op.IfRevision = copt.IfRevision
+
+	err := d.stage(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+func (d *stagedStore) Delete(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.DeleteOption) error {
+
+	copt := options.CommonOptionHolderFactory()
+	for _, o := range opt {
+		err := o.ApplyFunction()(&copt)
+		if err != nil {
+			return err
+		}
+	}
+
+	op := store.DeleteOp(identity)
+	op.IfRevision = copt.IfRevision
+
+	return d.stage(ctx, op)
+}
+
+func (d *stagedStore) Batch(
+	ctx context.Context,
+	ops []store.Op) (store.ObjectList, error) {
+
+	res := store.ObjectList{}
+	for _, op := range ops {
+		err := d.stage(ctx, op)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, op.Object)
+	}
+
+	return res, nil
 }
@@ -6,8 +6,10 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/wazofski/storz/filter"
 	"github.com/wazofski/storz/generated"
 	"github.com/wazofski/storz/store"
+	"github.com/wazofski/storz/store/options"
 )
 
 func WorldCreateCb(obj store.Object, str store.Store) error {
@@ -75,4 +77,88 @@ var _ = Describe("react", func() {
 		Expect(ret).ToNot(BeNil())
 		Expect(err).To(BeNil())
 	})
+
+	It("can WATCH changes", func() {
+		wctx, wcancel := context.WithCancel(context.Background())
+		events, err := store.Watch(wctx, str,
+			generated.SecondWorldKindIdentity(),
+			options.Filter(filter.Eq("spec.name", "ghi")))
+		Expect(err).To(BeNil())
+
+		ignored := generated.SecondWorldFactory()
+		ignored.Spec().SetName("jkl")
+		_, err = str.Create(ctx, ignored)
+		Expect(err).To(BeNil())
+
+		world := generated.SecondWorldFactory()
+		world.Spec().SetName("ghi")
+		_, err = str.Create(ctx, world)
+		Expect(err).To(BeNil())
+
+		err = str.Delete(ctx, generated.SecondWorldIdentity("ghi"))
+		Expect(err).To(BeNil())
+
+		var evt store.Event
+		Eventually(events).Should(Receive(&evt))
+		Expect(evt.Type).To(Equal(store.EventCreated))
+		Expect(evt.Object.PrimaryKey()).To(Equal("ghi"))
+
+		Eventually(events).Should(Receive(&evt))
+		Expect(evt.Type).To(Equal(store.EventDeleted))
+		Expect(evt.Object.PrimaryKey()).To(Equal("ghi"))
+
+		wcancel()
+		Eventually(events).Should(BeClosed())
+	})
+
+	It("can reject a BATCH", func() {
+		second := generated.SecondWorldFactory()
+		second.Spec().SetName("mno")
+
+		_, err := store.Batch(ctx, str, []store.Op{
+			store.CreateOp(second),
+			store.DeleteOp(generated.WorldIdentity("abc")),
+		})
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(Equal("cannot delete"))
+
+		_, err = str.Get(ctx, generated.SecondWorldIdentity("mno"))
+		Expect(err).ToNot(BeNil())
+
+		ret, err := store.Batch(ctx, str, []store.Op{
+			store.CreateOp(second),
+		})
+		Expect(err).To(BeNil())
+		Expect(len(ret)).To(Equal(1))
+	})
+
+	It("can apply callback writes along with a BATCH", func() {
+		err := str.Delete(ctx, generated.SecondWorldIdentity("def"))
+		Expect(err).To(BeNil())
+
+		ret, err := str.Get(ctx, generated.WorldIdentity("abc"))
+		Expect(err).To(BeNil())
+
+		stale := store.UpdateOp(generated.SecondWorldIdentity("mno"), generated.SecondWorldFactory())
+		stale.IfRevision = 99
+
+		_, err = store.Batch(ctx, str, []store.Op{
+			store.UpdateOp(generated.WorldIdentity("abc"), ret),
+			stale,
+		})
+		Expect(err).ToNot(BeNil())
+
+		_, err = str.Get(ctx, generated.SecondWorldIdentity("def"))
+		Expect(err).ToNot(BeNil())
+
+		res, err := store.Batch(ctx, str, []store.Op{
+			store.UpdateOp(generated.WorldIdentity("abc"), ret),
+		})
+		Expect(err).To(BeNil())
+		Expect(len(res)).To(Equal(1))
+		Expect(res[0].PrimaryKey()).To(Equal("abc"))
+
+		_, err = str.Get(ctx, generated.SecondWorldIdentity("def"))
+		Expect(err).To(BeNil())
+	})
 })
@@ -0,0 +1,209 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/store"
+	"github.com/wazofski/storz/store/options"
+	"github.com/wazofski/storz/utils"
+)
+
+const BulkPath = "/_bulk"
+
+// BulkItem is a single op of a bulk request.
+// Creates need the kind, updates and deletes the identity.
+type BulkItem struct {
+	Op       store.OpType         `json:"op"`
+	Kind     string               `json:"kind,omitempty"`
+	Identity store.ObjectIdentity `json:"identity,omitempty"`
+	Revision int64                `json:"revision,omitempty"`
+	Object   *json.RawMessage     `json:"object,omitempty"`
+}
+
+// BulkResult reports the outcome of the bulk item at the same position
+type BulkResult struct {
+	Status int              `json:"status"`
+	Error  string           `json:"error,omitempty"`
+	Object *json.RawMessage `json:"object,omitempty"`
+}
+
+func makeBulkHandler(server *_Server) _HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		prepResponse(w, r)
+
+		if r.Method != http.MethodPost {
+			reportError(w,
+				constants.ErrInvalidMethod,
+				http.StatusMethodNotAllowed)
+			return
+		}
+
+		data, err := utils.ReadStream(r.Body)
+		if err != nil {
+			reportError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		items, err := parseBulk(data)
+		if err != nil {
+			reportError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		res := []BulkResult{}
+		for _, item := range items {
+			res = append(res, server.handleBulkItem(item))
+		}
+
+		resp, _ := json.Marshal(res)
+		writeResponse(w, resp)
+	}
+}
+
+// bulk payloads are either a JSON array or JSON lines
+func parseBulk(data []byte) ([]BulkItem, error) {
+	items := []BulkItem{}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		err := json.Unmarshal(trimmed, &items)
+		return items, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	for {
+		item := BulkItem{}
+		err := decoder.Decode(&item)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func bulkError(err error, code int) BulkResult {
+	return BulkResult{
+		Status: code,
+		Error:  err.Error(),
+	}
+}
+
+func (d *_Server) handleBulkItem(item BulkItem) BulkResult {
+	kind := item.Kind
+	action := ActionCreate
+
+	switch item.Op {
+	case store.OpCreate:
+		if len(kind) == 0 {
+			return bulkError(constants.ErrInvalidPath, http.StatusBadRequest)
+		}
+	case store.OpUpdate, store.OpDelete:
+		action = ActionUpdate
+		if item.Op == store.OpDelete {
+			action = ActionDelete
+		}
+
+		if len(item.Identity) == 0 {
+			return bulkError(constants.ErrInvalidPath, http.StatusBadRequest)
+		}
+
+		if item.Identity.Type() == "id" {
+			existing, err := d.Store.Get(d.Context, item.Identity)
+			if err != nil {
+				return bulkError(err, http.StatusNotFound)
+			}
+			kind = existing.Metadata().Kind()
+		} else {
+			kind = item.Identity.Type()
+		}
+	default:
+		return bulkError(constants.ErrInvalidMethod, http.StatusBadRequest)
+	}
+
+	proto := d.Schema.ObjectForKind(kind)
+	if proto == nil {
+		return bulkError(
+			fmt.Errorf("unknown kind %s", kind),
+			http.StatusBadRequest)
+	}
+
+	// method validation
+	kind = proto.Metadata().Kind()
+	objMethods := d.Exposed[kind]
+	if objMethods == nil || !slices.Contains(objMethods, action) {
+		return bulkError(constants.ErrInvalidMethod, http.StatusMethodNotAllowed)
+	}
+
+	var robject store.Object = nil
+	if item.Op != store.OpDelete {
+		if item.Object == nil {
+			return bulkError(constants.ErrObjectNil, http.StatusBadRequest)
+		}
+
+		var err error
+		robject, err = utils.UnmarshalObject(*item.Object, d.Schema, kind)
+		if err != nil {
+			return bulkError(err, writeStatus(err, http.StatusBadRequest))
+		}
+	}
+
+	var ret store.Object = nil
+	var err error = nil
+	switch item.Op {
+	case store.OpCreate:
+		ret, err = d.Store.Create(d.Context, robject)
+		if err != nil {
+			return bulkError(err, writeStatus(err, http.StatusNotAcceptable))
+		}
+	case store.OpUpdate:
+		opts := []options.UpdateOption{}
+		if item.Revision > 0 {
+			opts = append(opts, options.IfRevision(item.Revision))
+		}
+
+		ret, err = d.Store.Update(d.Context, item.Identity, robject, opts...)
+		if err != nil {
+			return bulkError(err, writeStatus(err, http.StatusNotAcceptable))
+		}
+	case store.OpDelete:
+		opts := []options.DeleteOption{}
+		if item.Revision > 0 {
+			opts = append(opts, options.IfRevision(item.Revision))
+		}
+
+		err = d.Store.Delete(d.Context, item.Identity, opts...)
+		if err == constants.ErrConflict {
+			return bulkError(err, http.StatusPreconditionFailed)
+		}
+		if err != nil {
+			return bulkError(err, http.StatusNotFound)
+		}
+	}
+
+	res := BulkResult{
+		Status: http.StatusOK,
+	}
+
+	if ret != nil {
+		data, err := json.Marshal(ret)
+		if err != nil {
+			return bulkError(err, http.StatusInternalServerError)
+		}
+		raw := json.RawMessage(data)
+		res.Object = &raw
+	}
+
+	return res
+}
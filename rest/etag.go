@@ -0,0 +1,39 @@
+package rest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/wazofski/storz/store"
+)
+
+const (
+	ETagHeader        = "ETag"
+	IfMatchHeader     = "If-Match"
+	IfNoneMatchHeader = "If-None-Match"
+)
+
+func ETag(revision int64) string {
+	return fmt.Sprintf("\"%d\"", revision)
+}
+
+func objectETag(obj store.Object) string {
+	return ETag(obj.Metadata().Revision())
+}
+
+// parses a single entity tag into a revision, 0 stands for the * wildcard
+func ParseETag(tag string) (int64, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "*" {
+		return 0, nil
+	}
+
+	tag = strings.TrimPrefix(tag, "W/")
+	rev, err := strconv.ParseInt(strings.Trim(tag, "\""), 10, 64)
+	if err != nil || rev <= 0 {
+		return 0, fmt.Errorf("invalid entity tag %s", tag)
+	}
+
+	return rev, nil
+}
@@ -40,6 +40,15 @@ func (d *internalStore) Create(
 
 	d.Log.Printf("create %s", obj.PrimaryKey())
 
+	original, err := d.prepareCreate(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Store.Create(ctx, original, opt...)
+}
+
+func (d *internalStore) prepareCreate(obj store.Object) (store.Object, error) {
 	// initialize metadata
 	original := d.Schema.ObjectForKind(obj.Metadata().Kind())
 	if original == nil {
@@ -56,8 +65,10 @@ func (d *internalStore) Create(
 
 	ms.SetIdentity(store.ObjectIdentityFactory())
 	ms.SetCreated(utils.Timestamp())
+	ms.SetLabels(obj.Metadata().Labels())
+	ms.SetAnnotations(obj.Metadata().Annotations())
 
-	return d.Store.Create(ctx, original, opt...)
+	return original, store.Validate(original)
 }
 
 func (d *internalStore) Update(
@@ -71,6 +82,20 @@ func (d *internalStore) Update(
 	}
 
 	d.Log.Printf("update %s", identity.Path())
+
+	original, err := d.prepareUpdate(ctx, identity, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Store.Update(ctx, identity, original, opt...)
+}
+
+func (d *internalStore) prepareUpdate(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	obj store.Object) (store.Object, error) {
+
 	// read the real object
 	original, err := d.Store.Get(ctx, identity)
 
@@ -92,10 +117,17 @@ func (d *internalStore) Update(
 		}
 	}
 
+	// labels and annotations are kept unless the request sets them
 	ms := original.Metadata().(store.MetaSetter)
 	ms.SetUpdated(utils.Timestamp())
+	if obj != nil && obj.Metadata().Labels() != nil {
+		ms.SetLabels(obj.Metadata().Labels())
+	}
+	if obj != nil && obj.Metadata().Annotations() != nil {
+		ms.SetAnnotations(obj.Metadata().Annotations())
+	}
 
-	return d.Store.Update(ctx, identity, original, opt...)
+	return original, store.Validate(original)
 }
 
 func (d *internalStore) Delete(
@@ -127,3 +159,62 @@ func (d *internalStore) List(
 
 	return d.Store.List(ctx, identity, opt...)
 }
+
+func (d *internalStore) ListPage(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (store.Page, error) {
+
+	d.Log.Printf("list %s", identity.Type())
+
+	return store.ListPage(ctx, d.Store, identity, opt...)
+}
+
+func (d *internalStore) Count(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (int, error) {
+
+	d.Log.Printf("count %s", identity.Type())
+
+	return store.Count(ctx, d.Store, identity, opt...)
+}
+
+func (d *internalStore) Watch(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.WatchOption) (<-chan store.Event, error) {
+
+	d.Log.Printf("watch %s", identity.Type())
+
+	return store.Watch(ctx, d.Store, identity, opt...)
+}
+
+func (d *internalStore) Batch(
+	ctx context.Context,
+	ops []store.Op) (store.ObjectList, error) {
+
+	d.Log.Printf("batch %d", len(ops))
+
+	prepared := []store.Op{}
+	for _, op := range ops {
+		err := op.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.Type {
+		case store.OpCreate:
+			op.Object, err = d.prepareCreate(op.Object)
+		case store.OpUpdate:
+			op.Object, err = d.prepareUpdate(ctx, op.Identity, op.Object)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		prepared = append(prepared, op)
+	}
+
+	return store.Batch(ctx, d.Store, prepared)
+}
@@ -6,12 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
 	"golang.org/x/exp/slices"
 
+	"github.com/wazofski/storz/filter"
 	"github.com/wazofski/storz/internal/constants"
 	"github.com/wazofski/storz/internal/logger"
 	"github.com/wazofski/storz/store"
@@ -23,11 +25,24 @@ var log = logger.Factory("rest server")
 
 const (
 	PropFilterArg  = "pf"
+	FilterArg      = "filter"
 	KeyFilterArg   = "kf"
 	IncrementalArg = "inc"
 	PageSizeArg    = "pageSize"
 	PageOffsetArg  = "pageOffset"
 	OrderByArg     = "orderBy"
+	ContinueArg    = "continue"
+	FieldsArg      = "fields"
+	LabelsArg      = "labels"
+	WatchArg       = "watch"
+)
+
+// DescendingPrefix marks descending keys of the orderBy argument
+const DescendingPrefix = "-"
+
+const (
+	ContinueHeader   = "X-Continue"
+	TotalCountHeader = "X-Total-Count"
 )
 
 type _HandlerFunc func(http.ResponseWriter, *http.Request)
@@ -36,6 +51,7 @@ type _Server struct {
 	Schema  store.SchemaHolder
 	Store   store.Store
 	Context context.Context
+	Streams context.Context
 	Router  *mux.Router
 	Exposed map[string][]Action
 }
@@ -46,6 +62,10 @@ func (d *_Server) Listen(port int) context.CancelFunc {
 		Handler: d.Router,
 	}
 
+	streams, stop := context.WithCancel(context.Background())
+	d.Streams = streams
+	srv.RegisterOnShutdown(stop)
+
 	go func() {
 		err := srv.ListenAndServe()
 		if errors.Is(err, http.ErrServerClosed) {
@@ -88,11 +108,13 @@ func Server(schema store.SchemaHolder, stor store.Store, exposed ..._TypeMethods
 		Schema:  schema,
 		Store:   store.New(schema, internalFactory(stor)),
 		Context: context.Background(),
+		Streams: context.Background(),
 		Router:  mux.NewRouter(),
 		Exposed: make(map[string][]Action),
 	}
 
 	addHandler(server.Router, "/id/{id}", makeIdHandler(server))
+	addHandler(server.Router, BulkPath, makeBulkHandler(server))
 	for _, e := range exposed {
 		server.Exposed[e.Kind] = e.Actions
 
@@ -125,7 +147,7 @@ func makeIdHandler(server *_Server) _HandlerFunc {
 			kind := existing.Metadata().Kind()
 			data, err := utils.ReadStream(r.Body)
 			if err == nil {
-				robject, _ = utils.UnmarshalObject(data, server.Schema, kind)
+				robject, err = utils.UnmarshalObject(data, server.Schema, kind)
 			}
 
 			// method validation
@@ -136,6 +158,11 @@ func makeIdHandler(server *_Server) _HandlerFunc {
 					http.StatusMethodNotAllowed)
 				return
 			}
+
+			if errors.Is(err, constants.ErrInvalidObject) {
+				reportError(w, err, http.StatusUnprocessableEntity)
+				return
+			}
 		}
 
 		server.handlePath(w, r, id, robject)
@@ -149,7 +176,7 @@ func makeObjectHandler(server *_Server, t string, methods []Action) _HandlerFunc
 		id := store.ObjectIdentity(strings.ToLower(t) + "/" + mux.Vars(r)["pkey"])
 		data, err := utils.ReadStream(r.Body)
 		if err == nil {
-			robject, _ = utils.UnmarshalObject(data, server.Schema, t)
+			robject, err = utils.UnmarshalObject(data, server.Schema, t)
 		}
 
 		// method validation
@@ -160,6 +187,11 @@ func makeObjectHandler(server *_Server, t string, methods []Action) _HandlerFunc
 			return
 		}
 
+		if errors.Is(err, constants.ErrInvalidObject) {
+			reportError(w, err, http.StatusUnprocessableEntity)
+			return
+		}
+
 		server.handlePath(w, r, id, robject)
 	}
 }
@@ -178,29 +210,33 @@ func makeTypeHandler(server *_Server, t string, methods []Action) _HandlerFunc {
 
 		switch r.Method {
 		case http.MethodGet:
-			opts := []options.ListOption{}
-
 			vals := r.URL.Query()
-			filter, ok := vals[PropFilterArg]
-			if ok {
-				flt := options.PropFilterSetting{}
-				err := json.Unmarshal([]byte(filter[0]), &flt)
-				if err != nil {
-					reportError(w, err, http.StatusBadRequest)
-					return
-				}
-				opts = append(opts, options.PropFilter(flt.Key, flt.Value))
+			filters, err := filterOptions(vals)
+			if err != nil {
+				reportError(w, err, http.StatusBadRequest)
+				return
 			}
 
-			keyFilter, ok := vals[KeyFilterArg]
+			identity := store.ObjectIdentity(
+				fmt.Sprintf("%s/", strings.ToLower(t)))
+
+			watch, ok := vals[WatchArg]
 			if ok {
-				flt := options.KeyFilterSetting{}
-				err := json.Unmarshal([]byte(keyFilter[0]), &flt)
+				wt := false
+				err := json.Unmarshal([]byte(watch[0]), &wt)
 				if err != nil {
 					reportError(w, err, http.StatusBadRequest)
 					return
 				}
-				opts = append(opts, options.KeyFilter(flt...))
+				if wt {
+					server.handleWatch(w, r, identity, filters)
+					return
+				}
+			}
+
+			opts := []options.ListOption{}
+			for _, f := range filters {
+				opts = append(opts, f)
 			}
 
 			pageSize, ok := vals[PageSizeArg]
@@ -215,36 +251,64 @@ func makeTypeHandler(server *_Server, t string, methods []Action) _HandlerFunc {
 				opts = append(opts, options.PageOffset(ps))
 			}
 
-			orderBy, ok := vals[OrderByArg]
+			token, ok := vals[ContinueArg]
 			if ok {
-				ob := orderBy[0]
-				opts = append(opts, options.OrderBy(ob))
+				opts = append(opts, options.Continue(token[0]))
 			}
 
-			orderInc, ok := vals[IncrementalArg]
+			fields, ok := vals[FieldsArg]
 			if ok {
-				ob := true
-				err := json.Unmarshal([]byte(orderInc[0]), &ob)
+				opts = append(opts, options.Fields(parseFields(fields[0])...))
+			}
+
+			labels, ok := vals[LabelsArg]
+			if ok {
+				sel := map[string]string{}
+				err := json.Unmarshal([]byte(labels[0]), &sel)
 				if err != nil {
 					reportError(w, err, http.StatusBadRequest)
 					return
 				}
-				if !ob {
-					opts = append(opts, options.OrderDescending())
+				opts = append(opts, options.LabelSelector(sel))
+			}
+
+			// inc=false reverses the direction of every key,
+			// of the primary key order when there is none
+			inc := true
+			if orderInc, ok := vals[IncrementalArg]; ok {
+				err := json.Unmarshal([]byte(orderInc[0]), &inc)
+				if err != nil {
+					reportError(w, err, http.StatusBadRequest)
+					return
 				}
 			}
 
-			ret, err := server.Store.List(
+			orderBy := ""
+			if val, ok := vals[OrderByArg]; ok {
+				orderBy = val[0]
+			}
+
+			opts = append(opts, orderOptions(orderBy, inc)...)
+
+			page, err := store.ListPage(
 				server.Context,
-				store.ObjectIdentity(
-					fmt.Sprintf("%s/", strings.ToLower(t))),
+				server.Store,
+				identity,
 				opts...)
 
 			if err != nil {
 				reportError(w, err, http.StatusBadRequest)
 				return
-			} else if ret != nil {
-				resp, _ := json.Marshal(ret)
+			} else if page.Objects != nil {
+				if len(page.Continue) > 0 {
+					w.Header().Set(ContinueHeader, page.Continue)
+				}
+
+				total, err := store.Count(server.Context, server.Store, identity, opts...)
+				if err == nil {
+					w.Header().Set(TotalCountHeader, strconv.Itoa(total))
+				}
+				resp, _ := json.Marshal(page.Objects)
 				writeResponse(w, resp)
 			}
 		case http.MethodPost:
@@ -260,7 +324,7 @@ func makeTypeHandler(server *_Server, t string, methods []Action) _HandlerFunc {
 			if err != nil {
 				reportError(w,
 					err,
-					http.StatusBadRequest)
+					writeStatus(err, http.StatusBadRequest))
 				return
 			}
 
@@ -273,35 +337,116 @@ func makeTypeHandler(server *_Server, t string, methods []Action) _HandlerFunc {
 	}
 }
 
+func filterOptions(vals url.Values) ([]options.FilterOption, error) {
+	opts := []options.FilterOption{}
+
+	propFilter, ok := vals[PropFilterArg]
+	if ok {
+		flt := options.PropFilterSetting{}
+		err := json.Unmarshal([]byte(propFilter[0]), &flt)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, options.PropFilter(flt.Key, flt.Value))
+	}
+
+	expr, ok := vals[FilterArg]
+	if ok {
+		flt, err := filter.Parse([]byte(expr[0]))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, options.Filter(flt))
+	}
+
+	keyFilter, ok := vals[KeyFilterArg]
+	if ok {
+		flt := options.KeyFilterSetting{}
+		err := json.Unmarshal([]byte(keyFilter[0]), &flt)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, options.KeyFilter(flt...))
+	}
+
+	return opts, nil
+}
+
 func (d *_Server) handlePath(
 	w http.ResponseWriter,
 	r *http.Request,
 	identity store.ObjectIdentity,
 	object store.Object) {
 
-	var ret store.Object = nil
+	var ifMatch int64 = 0
 	var err error = nil
+	if tag := r.Header.Get(IfMatchHeader); len(tag) > 0 {
+		ifMatch, err = ParseETag(tag)
+		if err != nil {
+			reportError(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	var ret store.Object = nil
 	switch r.Method {
 	case http.MethodGet:
-		ret, err = d.Store.Get(d.Context, identity)
+		opts := []options.GetOption{}
+		if fields := r.URL.Query().Get(FieldsArg); len(fields) > 0 {
+			opts = append(opts, options.Fields(parseFields(fields)...))
+		}
+
+		ret, err = d.Store.Get(d.Context, identity, opts...)
+		if errors.Is(err, constants.ErrInvalidFilter) {
+			reportError(w, err, http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			reportError(w, err, http.StatusNotFound)
 			return
 		}
+
+		// trimmed objects without a revision carry no ETag
+		if ret.Metadata().Revision() == 0 {
+			break
+		}
+
+		if tag := r.Header.Get(IfNoneMatchHeader); len(tag) > 0 {
+			rev, err := ParseETag(tag)
+			if err == nil && (rev == 0 || rev == ret.Metadata().Revision()) {
+				w.Header().Set(ETagHeader, objectETag(ret))
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
 	case http.MethodPost:
 		ret, err = d.Store.Create(d.Context, object)
 		if err != nil {
-			reportError(w, err, http.StatusNotAcceptable)
+			reportError(w, err, writeStatus(err, http.StatusNotAcceptable))
 			return
 		}
 	case http.MethodPut:
-		ret, err = d.Store.Update(d.Context, identity, object)
+		opts := []options.UpdateOption{}
+		if ifMatch > 0 {
+			opts = append(opts, options.IfRevision(ifMatch))
+		}
+
+		ret, err = d.Store.Update(d.Context, identity, object, opts...)
 		if err != nil {
-			reportError(w, err, http.StatusNotAcceptable)
+			reportError(w, err, writeStatus(err, http.StatusNotAcceptable))
 			return
 		}
 	case http.MethodDelete:
-		err = d.Store.Delete(d.Context, identity)
+		opts := []options.DeleteOption{}
+		if ifMatch > 0 {
+			opts = append(opts, options.IfRevision(ifMatch))
+		}
+
+		err = d.Store.Delete(d.Context, identity, opts...)
+		if err == constants.ErrConflict {
+			reportError(w, err, http.StatusPreconditionFailed)
+			return
+		}
 		if err != nil {
 			reportError(w, err, http.StatusNotFound)
 			return
@@ -309,11 +454,59 @@ func (d *_Server) handlePath(
 	}
 
 	if err == nil && ret != nil {
+		if ret.Metadata().Revision() > 0 {
+			w.Header().Set(ETagHeader, objectETag(ret))
+		}
 		resp, _ := json.Marshal(ret)
 		writeResponse(w, resp)
 	}
 }
 
+func orderOptions(val string, inc bool) []options.ListOption {
+	res := []options.ListOption{}
+	for _, k := range parseFields(val) {
+		descending := strings.HasPrefix(k, DescendingPrefix)
+		res = append(res, options.OrderBy(strings.TrimPrefix(k, DescendingPrefix)))
+		if descending == inc {
+			res = append(res, options.OrderDescending())
+		}
+	}
+
+	if len(res) == 0 && !inc {
+		res = append(res, options.OrderDescending())
+	}
+
+	return res
+}
+
+func parseFields(val string) []string {
+	res := []string{}
+	for _, f := range strings.Split(val, ",") {
+		f = strings.TrimSpace(f)
+		if len(f) > 0 {
+			res = append(res, f)
+		}
+	}
+
+	return res
+}
+
+// writeStatus maps the errors of failed writes to status codes
+func writeStatus(err error, status int) int {
+	switch err {
+	case constants.ErrConflict:
+		return http.StatusPreconditionFailed
+	case constants.ErrUniqueViolation:
+		return http.StatusConflict
+	}
+
+	if errors.Is(err, constants.ErrInvalidObject) {
+		return http.StatusUnprocessableEntity
+	}
+
+	return status
+}
+
 func reportError(w http.ResponseWriter, err error, code int) {
 	http.Error(w, err.Error(), code)
 }
@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/store"
+	"github.com/wazofski/storz/store/options"
+)
+
+func (d *_Server) handleWatch(
+	w http.ResponseWriter,
+	r *http.Request,
+	identity store.ObjectIdentity,
+	filters []options.FilterOption) {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		reportError(w, constants.ErrUnsupported, http.StatusNotImplemented)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-d.Streams.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	opts := []options.WatchOption{}
+	for _, f := range filters {
+		opts = append(opts, f)
+	}
+
+	events, err := store.Watch(ctx, d.Store, identity, opts...)
+	if err != nil {
+		code := http.StatusBadRequest
+		if err == constants.ErrUnsupported {
+			code = http.StatusNotImplemented
+		}
+		reportError(w, err, code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for evt := range events {
+		data, err := json.Marshal(evt.Object)
+		if err != nil {
+			log.Printf("%s", err)
+			continue
+		}
+
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, data)
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,207 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/store"
+	"github.com/wazofski/storz/utils"
+)
+
+// Directory keeps track of the kind owning each object identity
+type Directory interface {
+	Lookup(context.Context, store.ObjectIdentity) (string, error)
+	Register(context.Context, store.ObjectIdentity, string) error
+	Unregister(context.Context, store.ObjectIdentity) error
+}
+
+type memoryDirectory struct {
+	Lock  sync.RWMutex
+	Kinds map[store.ObjectIdentity]string
+}
+
+func MemoryDirectory() Directory {
+	return &memoryDirectory{
+		Kinds: make(map[store.ObjectIdentity]string),
+	}
+}
+
+func (d *memoryDirectory) Lookup(
+	ctx context.Context,
+	identity store.ObjectIdentity) (string, error) {
+
+	d.Lock.RLock()
+	defer d.Lock.RUnlock()
+
+	kind, ok := d.Kinds[identity]
+	if !ok {
+		return "", constants.ErrNoSuchObject
+	}
+
+	return kind, nil
+}
+
+func (d *memoryDirectory) Register(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	kind string) error {
+
+	d.Lock.Lock()
+	defer d.Lock.Unlock()
+
+	d.Kinds[identity] = kind
+
+	return nil
+}
+
+func (d *memoryDirectory) Unregister(
+	ctx context.Context,
+	identity store.ObjectIdentity) error {
+
+	d.Lock.Lock()
+	defer d.Lock.Unlock()
+
+	delete(d.Kinds, identity)
+
+	return nil
+}
+
+type storeDirectory struct {
+	Store store.Store
+}
+
+// StoreDirectory keeps the directory entries in a store
+// created with the DirectorySchema
+func StoreDirectory(st store.Store) Directory {
+	return &storeDirectory{
+		Store: st,
+	}
+}
+
+func (d *storeDirectory) Lookup(
+	ctx context.Context,
+	identity store.ObjectIdentity) (string, error) {
+
+	ret, err := d.Store.Get(ctx, entryIdentity(identity))
+	if err != nil {
+		return "", err
+	}
+
+	return ret.(*entry).Spec_.Kind, nil
+}
+
+func (d *storeDirectory) Register(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	kind string) error {
+
+	obj := entryFactory()
+	obj.Spec_.Identity = identity
+	obj.Spec_.Kind = kind
+
+	_, err := d.Store.Create(ctx, obj)
+	if err == constants.ErrObjectExists {
+		_, err = d.Store.Update(ctx, entryIdentity(identity), obj)
+	}
+
+	return err
+}
+
+func (d *storeDirectory) Unregister(
+	ctx context.Context,
+	identity store.ObjectIdentity) error {
+
+	err := d.Store.Delete(ctx, entryIdentity(identity))
+	if err == constants.ErrNoSuchObject {
+		return nil
+	}
+
+	return err
+}
+
+const entryKind = "DirectoryEntry"
+
+type entrySpec struct {
+	Identity store.ObjectIdentity `json:"identity"`
+	Kind     string               `json:"kind"`
+}
+
+type entry struct {
+	Meta_ *store.Meta `json:"metadata"`
+	Spec_ *entrySpec  `json:"spec"`
+}
+
+func entryFactory() *entry {
+	meta := store.MetaFactory(entryKind)
+	return &entry{
+		Meta_: &meta,
+		Spec_: &entrySpec{},
+	}
+}
+
+func entryIdentity(identity store.ObjectIdentity) store.ObjectIdentity {
+	return store.ObjectIdentity(
+		fmt.Sprintf("%s/%s",
+			strings.ToLower(entryKind),
+			identity))
+}
+
+func (e *entry) Metadata() store.Meta {
+	return *e.Meta_
+}
+
+func (e *entry) PrimaryKey() string {
+	return string(e.Spec_.Identity)
+}
+
+func (e *entry) Clone() store.Object {
+	return utils.CloneObject(e, DirectorySchema())
+}
+
+func (e *entry) UnmarshalJSON(data []byte) error {
+	rawMap := make(map[string]*json.RawMessage)
+	err := json.Unmarshal(data, &rawMap)
+	if err != nil {
+		return err
+	}
+
+	for key, rawValue := range rawMap {
+		if rawValue == nil {
+			continue
+		}
+		switch key {
+		case "metadata":
+			err = json.Unmarshal(*rawValue, e.Meta_)
+		case "spec":
+			err = json.Unmarshal(*rawValue, e.Spec_)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type directorySchema struct{}
+
+// DirectorySchema describes the objects kept by a StoreDirectory
+func DirectorySchema() store.SchemaHolder {
+	return directorySchema{}
+}
+
+func (directorySchema) ObjectForKind(kind string) store.Object {
+	if strings.EqualFold(kind, entryKind) {
+		return entryFactory()
+	}
+
+	return nil
+}
+
+func (directorySchema) Types() []string {
+	return []string{entryKind}
+}
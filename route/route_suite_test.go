@@ -0,0 +1,50 @@
+package route_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/wazofski/storz/generated"
+	"github.com/wazofski/storz/memory"
+	"github.com/wazofski/storz/route"
+	"github.com/wazofski/storz/store"
+)
+
+func TestRoute(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "route suite")
+}
+
+var deflt store.Store
+var worlds store.Store
+var routed store.Store
+var directory route.Directory
+var indexed store.Store
+var ctx context.Context = context.Background()
+
+var _ = BeforeSuite(func() {
+	sch := generated.Schema()
+
+	deflt = store.New(sch, memory.Factory())
+	worlds = store.New(sch, memory.Factory())
+
+	routed = store.New(sch,
+		route.Factory(deflt,
+			route.Mapping{
+				Kind:  generated.WorldKind(),
+				Store: worlds,
+			}))
+
+	directory = route.StoreDirectory(
+		store.New(route.DirectorySchema(), memory.Factory()))
+
+	indexed = store.New(sch,
+		route.DirectoryFactory(directory,
+			store.New(sch, memory.Factory()),
+			route.Mapping{
+				Kind:  generated.WorldKind(),
+				Store: worlds,
+			}))
+})
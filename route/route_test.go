@@ -0,0 +1,137 @@
+package route_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/wazofski/storz/generated"
+)
+
+var _ = Describe("route", func() {
+
+	It("can route by kind", func() {
+		world := generated.WorldFactory()
+		world.Spec().SetName("abc")
+		_, err := routed.Create(ctx, world)
+		Expect(err).To(BeNil())
+
+		second := generated.SecondWorldFactory()
+		second.Spec().SetName("abc")
+		_, err = routed.Create(ctx, second)
+		Expect(err).To(BeNil())
+
+		ret, err := worlds.Get(ctx, generated.WorldIdentity("abc"))
+		Expect(err).To(BeNil())
+		Expect(ret).ToNot(BeNil())
+
+		_, err = deflt.Get(ctx, generated.WorldIdentity("abc"))
+		Expect(err).ToNot(BeNil())
+
+		ret, err = deflt.Get(ctx, generated.SecondWorldIdentity("abc"))
+		Expect(err).To(BeNil())
+		Expect(ret).ToNot(BeNil())
+
+		_, err = worlds.Get(ctx, generated.SecondWorldIdentity("abc"))
+		Expect(err).ToNot(BeNil())
+
+		list, err := routed.List(ctx, generated.WorldKindIdentity())
+		Expect(err).To(BeNil())
+		Expect(len(list)).To(Equal(1))
+
+		list, err = routed.List(ctx, generated.SecondWorldKindIdentity())
+		Expect(err).To(BeNil())
+		Expect(len(list)).To(Equal(1))
+	})
+
+	It("can route by id", func() {
+		ret, err := routed.Get(ctx, generated.WorldIdentity("abc"))
+		Expect(err).To(BeNil())
+		world := ret.(generated.World)
+
+		ret, err = routed.Get(ctx, world.Metadata().Identity())
+		Expect(err).To(BeNil())
+		Expect(ret.(generated.World).Spec().Name()).To(Equal("abc"))
+
+		world.Spec().SetDescription("def")
+		_, err = routed.Update(ctx, world.Metadata().Identity(), world)
+		Expect(err).To(BeNil())
+
+		ret, err = worlds.Get(ctx, generated.WorldIdentity("abc"))
+		Expect(err).To(BeNil())
+		Expect(ret.(generated.World).Spec().Description()).To(Equal("def"))
+
+		err = routed.Delete(ctx, world.Metadata().Identity())
+		Expect(err).To(BeNil())
+
+		_, err = worlds.Get(ctx, generated.WorldIdentity("abc"))
+		Expect(err).ToNot(BeNil())
+
+		_, err = routed.Get(ctx, world.Metadata().Identity())
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("can route to default", func() {
+		ret, err := routed.Get(ctx, generated.SecondWorldIdentity("abc"))
+		Expect(err).To(BeNil())
+		second := ret.(generated.SecondWorld)
+
+		ret, err = routed.Get(ctx, second.Metadata().Identity())
+		Expect(err).To(BeNil())
+		Expect(ret).ToNot(BeNil())
+
+		err = routed.Delete(ctx, generated.SecondWorldIdentity("abc"))
+		Expect(err).To(BeNil())
+
+		_, err = deflt.Get(ctx, generated.SecondWorldIdentity("abc"))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("can maintain the identity directory", func() {
+		world := generated.WorldFactory()
+		world.Spec().SetName("ghi")
+		ret, err := indexed.Create(ctx, world)
+		Expect(err).To(BeNil())
+		identity := ret.Metadata().Identity()
+
+		kind, err := directory.Lookup(ctx, identity)
+		Expect(err).To(BeNil())
+		Expect(kind).To(Equal(generated.WorldKind()))
+
+		ret, err = indexed.Get(ctx, identity)
+		Expect(err).To(BeNil())
+		Expect(ret.(generated.World).Spec().Name()).To(Equal("ghi"))
+
+		err = indexed.Delete(ctx, generated.WorldIdentity("ghi"))
+		Expect(err).To(BeNil())
+
+		_, err = directory.Lookup(ctx, identity)
+		Expect(err).ToNot(BeNil())
+
+		_, err = indexed.Get(ctx, identity)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("can register unknown identities", func() {
+		world := generated.WorldFactory()
+		world.Spec().SetName("jkl")
+		ret, err := worlds.Create(ctx, world)
+		Expect(err).To(BeNil())
+		identity := ret.Metadata().Identity()
+
+		_, err = directory.Lookup(ctx, identity)
+		Expect(err).ToNot(BeNil())
+
+		ret, err = indexed.Get(ctx, identity)
+		Expect(err).To(BeNil())
+		Expect(ret.(generated.World).Spec().Name()).To(Equal("jkl"))
+
+		kind, err := directory.Lookup(ctx, identity)
+		Expect(err).To(BeNil())
+		Expect(kind).To(Equal(generated.WorldKind()))
+
+		err = indexed.Delete(ctx, identity)
+		Expect(err).To(BeNil())
+
+		_, err = directory.Lookup(ctx, identity)
+		Expect(err).ToNot(BeNil())
+	})
+})
@@ -2,6 +2,7 @@ package route
 
 import (
 	"context"
+	"strings"
 
 	"github.com/wazofski/storz/internal/constants"
 	"github.com/wazofski/storz/internal/logger"
@@ -10,10 +11,11 @@ import (
 )
 
 type routeStore struct {
-	Schema  store.SchemaHolder
-	Log     logger.Logger
-	Mapping map[string]store.Store
-	Default store.Store
+	Schema    store.SchemaHolder
+	Log       logger.Logger
+	Mapping   map[string]store.Store
+	Default   store.Store
+	Directory Directory
 }
 
 type Mapping struct {
@@ -22,16 +24,25 @@ type Mapping struct {
 }
 
 func Factory(deault store.Store, mappings ...Mapping) store.Factory {
+	return DirectoryFactory(MemoryDirectory(), deault, mappings...)
+}
+
+func DirectoryFactory(
+	directory Directory,
+	deault store.Store,
+	mappings ...Mapping) store.Factory {
+
 	return func(schema store.SchemaHolder) (store.Store, error) {
 		client := &routeStore{
-			Schema:  schema,
-			Log:     logger.Factory("route"),
-			Mapping: make(map[string]store.Store),
-			Default: deault,
+			Schema:    schema,
+			Log:       logger.Factory("route"),
+			Mapping:   make(map[string]store.Store),
+			Default:   deault,
+			Directory: directory,
 		}
 
 		for _, m := range mappings {
-			client.Mapping[m.Kind] = m.Store
+			client.Mapping[strings.ToLower(m.Kind)] = m.Store
 		}
 
 		return client, nil
@@ -49,7 +60,19 @@ func (d *routeStore) Create(
 
 	d.Log.Printf("create %s", obj.PrimaryKey())
 
-	return d.Default.Create(ctx, obj, opt...)
+	kind := obj.Metadata().Kind()
+	ret, err := d.storeFor(kind).Create(ctx, obj, opt...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.Directory.Register(
+		ctx, directoryKey(ret.Metadata().Identity()), kind)
+	if err != nil {
+		d.Log.Printf("directory %s", err)
+	}
+
+	return ret, nil
 }
 
 func (d *routeStore) Update(
@@ -64,7 +87,7 @@ func (d *routeStore) Update(
 
 	d.Log.Printf("update %s", identity.Path())
 
-	return d.Default.Update(ctx, identity, obj, opt...)
+	return d.storeFor(obj.Metadata().Kind()).Update(ctx, identity, obj, opt...)
 }
 
 func (d *routeStore) Delete(
@@ -74,7 +97,31 @@ func (d *routeStore) Delete(
 
 	d.Log.Printf("delete %s", identity.Path())
 
-	return d.Default.Delete(ctx, identity, opt...)
+	st, err := d.resolve(ctx, identity)
+	if err != nil {
+		return err
+	}
+
+	owned := identity
+	if identity.Type() != "id" {
+		existing, err := st.Get(ctx, identity)
+		if err != nil {
+			return err
+		}
+		owned = existing.Metadata().Identity()
+	}
+
+	err = st.Delete(ctx, identity, opt...)
+	if err != nil {
+		return err
+	}
+
+	err = d.Directory.Unregister(ctx, directoryKey(owned))
+	if err != nil {
+		d.Log.Printf("directory %s", err)
+	}
+
+	return nil
 }
 
 func (d *routeStore) Get(
@@ -84,7 +131,12 @@ func (d *routeStore) Get(
 
 	d.Log.Printf("get %s", identity.Path())
 
-	return d.Default.Get(ctx, identity, opt...)
+	st, err := d.resolve(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return st.Get(ctx, identity, opt...)
 }
 
 func (d *routeStore) List(
@@ -94,5 +146,174 @@ func (d *routeStore) List(
 
 	d.Log.Printf("list %s", identity.Type())
 
-	return d.Default.List(ctx, identity, opt...)
+	return d.storeFor(identity.Type()).List(ctx, identity, opt...)
+}
+
+func (d *routeStore) ListPage(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (store.Page, error) {
+
+	d.Log.Printf("list %s", identity.Type())
+
+	return store.ListPage(ctx, d.storeFor(identity.Type()), identity, opt...)
+}
+
+func (d *routeStore) Count(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (int, error) {
+
+	d.Log.Printf("count %s", identity.Type())
+
+	return store.Count(ctx, d.storeFor(identity.Type()), identity, opt...)
+}
+
+func (d *routeStore) Watch(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.WatchOption) (<-chan store.Event, error) {
+
+	d.Log.Printf("watch %s", identity.Path())
+
+	st, err := d.resolve(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.Watch(ctx, st, identity, opt...)
+}
+
+func (d *routeStore) storeFor(kind string) store.Store {
+	st, ok := d.Mapping[strings.ToLower(kind)]
+	if ok {
+		return st
+	}
+
+	return d.Default
+}
+
+func (d *routeStore) stores() []store.Store {
+	res := []store.Store{d.Default}
+	for _, st := range d.Mapping {
+		found := false
+		for _, r := range res {
+			if r == st {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res = append(res, st)
+		}
+	}
+
+	return res
+}
+
+func directoryKey(identity store.ObjectIdentity) store.ObjectIdentity {
+	return store.ObjectIdentity(identity.Key())
+}
+
+// id/ identities carry no kind so the owning store is looked up
+// in the directory and found by probing when the directory misses
+func (d *routeStore) resolve(
+	ctx context.Context,
+	identity store.ObjectIdentity) (store.Store, error) {
+
+	if identity.Type() != "id" {
+		return d.storeFor(identity.Type()), nil
+	}
+
+	kind, err := d.Directory.Lookup(ctx, directoryKey(identity))
+	if err == nil {
+		return d.storeFor(kind), nil
+	}
+
+	for _, st := range d.stores() {
+		ret, err := st.Get(ctx, identity)
+		if err != nil {
+			continue
+		}
+
+		err = d.Directory.Register(
+			ctx, directoryKey(identity), ret.Metadata().Kind())
+		if err != nil {
+			d.Log.Printf("directory %s", err)
+		}
+
+		return st, nil
+	}
+
+	return nil, constants.ErrNoSuchObject
+}
+
+// a batch can only be atomic within a single store
+// so all of its ops have to route to the same one
+func (d *routeStore) Batch(
+	ctx context.Context,
+	ops []store.Op) (store.ObjectList, error) {
+
+	d.Log.Printf("batch %d", len(ops))
+
+	var target store.Store
+	owned := []store.ObjectIdentity{}
+	for _, op := range ops {
+		err := op.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		var st store.Store
+		identity := op.Identity
+		switch op.Type {
+		case store.OpCreate, store.OpUpdate:
+			st = d.storeFor(op.Object.Metadata().Kind())
+		case store.OpDelete:
+			st, err = d.resolve(ctx, identity)
+			if err != nil {
+				return nil, err
+			}
+			if identity.Type() != "id" {
+				existing, err := st.Get(ctx, identity)
+				if err != nil {
+					return nil, err
+				}
+				identity = existing.Metadata().Identity()
+			}
+		}
+
+		if target != nil && target != st {
+			return nil, constants.ErrUnsupported
+		}
+
+		target = st
+		owned = append(owned, identity)
+	}
+
+	if target == nil {
+		return store.ObjectList{}, nil
+	}
+
+	ret, err := store.Batch(ctx, target, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		var err error
+		switch op.Type {
+		case store.OpCreate:
+			err = d.Directory.Register(ctx,
+				directoryKey(ret[i].Metadata().Identity()),
+				op.Object.Metadata().Kind())
+		case store.OpDelete:
+			err = d.Directory.Unregister(ctx, directoryKey(owned[i]))
+		}
+		if err != nil {
+			d.Log.Printf("directory %s", err)
+		}
+	}
+
+	return ret, nil
 }
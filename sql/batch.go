@@ -0,0 +1,95 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/wazofski/storz/store"
+)
+
+func (d *sqlStore) Batch(
+	ctx context.Context,
+	ops []store.Op) (store.ObjectList, error) {
+
+	log.Printf("batch %d", len(ops))
+
+	for _, op := range ops {
+		err := op.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var res store.ObjectList
+	err := d.atomic(ctx, func(scoped *sqlStore) error {
+		var err error
+		res, err = scoped.apply(ctx, ops)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// atomic runs the function against a store scoped to a new transaction
+// which is committed when the function succeeds
+func (d *sqlStore) atomic(ctx context.Context, fn func(*sqlStore) error) error {
+	err := d.TestConnection()
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	scoped := &sqlStore{
+		Schema:         d.Schema,
+		DB:             d.DB,
+		Tx:             tx,
+		Driver:         d.Driver,
+		MakeConnection: d.MakeConnection,
+	}
+
+	err = fn(scoped)
+	if err != nil {
+		rerr := tx.Rollback()
+		if rerr != nil {
+			log.Printf("%s", rerr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (d *sqlStore) apply(
+	ctx context.Context,
+	ops []store.Op) (store.ObjectList, error) {
+
+	res := store.ObjectList{}
+	for _, op := range ops {
+		var ret store.Object
+		var err error
+
+		switch op.Type {
+		case store.OpCreate:
+			ret, err = d.Create(ctx, op.Object)
+		case store.OpUpdate:
+			ret, err = d.Update(ctx, op.Identity, op.Object, op.UpdateOptions()...)
+		case store.OpDelete:
+			err = d.Delete(ctx, op.Identity, op.DeleteOptions()...)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, ret)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,266 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wazofski/storz/filter"
+	"github.com/wazofski/storz/store"
+	"github.com/wazofski/storz/store/options"
+)
+
+var sqlOperators = map[filter.Operator]string{
+	filter.OpEq: "=",
+	filter.OpNe: "<>",
+	filter.OpLt: "<",
+	filter.OpLe: "<=",
+	filter.OpGt: ">",
+	filter.OpGe: ">=",
+}
+
+// keys are validated against the schema, quotes are escaped regardless
+func jsonPath(key string) string {
+	return fmt.Sprintf("json_extract(Object, '$.%s')",
+		strings.ReplaceAll(key, "'", "''"))
+}
+
+// label keys are quoted since they may contain dots and slashes
+func labelPath(key string) string {
+	return fmt.Sprintf(`$.metadata.labels."%s"`, key)
+}
+
+// the selected values are wrapped in a JSON array so that
+// nested objects and strings come back in the same form
+// the order values and the primary key follow the fields
+// so the continue token can be taken from trimmed rows
+func compileFields(fields []string, order []options.OrderSetting) string {
+	paths := []string{}
+	for _, f := range fields {
+		paths = append(paths, jsonPath(f))
+	}
+	for _, o := range order {
+		paths = append(paths, jsonPath(o.Key))
+	}
+	paths = append(paths, "Pkey")
+
+	return fmt.Sprintf("json_array(%s)", strings.Join(paths, ", "))
+}
+
+func compileFilter(expr *filter.Expression, path func(string) string) (string, []interface{}) {
+	switch expr.Op {
+	case filter.OpAnd, filter.OpOr:
+		clauses := []string{}
+		args := []interface{}{}
+		for _, x := range expr.Exprs {
+			c, a := compileFilter(x, path)
+			clauses = append(clauses, c)
+			args = append(args, a...)
+		}
+
+		join := " AND "
+		if expr.Op == filter.OpOr {
+			join = " OR "
+		}
+
+		return fmt.Sprintf("(%s)", strings.Join(clauses, join)), args
+	case filter.OpNot:
+		// terms are never NULL so negation matches filter.Match
+		c, a := compileFilter(expr.Exprs[0], path)
+		return fmt.Sprintf("(NOT %s)", c), a
+	case filter.OpIn:
+		values := expr.Value.([]interface{})
+		if len(values) == 0 {
+			return "(1 = 0)", []interface{}{}
+		}
+
+		marks := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		key := path(expr.Key)
+		return fmt.Sprintf("(%s IN (%s) AND %s IS NOT NULL)", key, marks, key),
+			values
+	}
+
+	key := path(expr.Key)
+	if expr.Value == nil {
+		switch expr.Op {
+		case filter.OpEq:
+			return fmt.Sprintf("(%s IS NULL)", key), []interface{}{}
+		case filter.OpNe:
+			return fmt.Sprintf("(%s IS NOT NULL)", key), []interface{}{}
+		}
+		return "(1 = 0)", []interface{}{}
+	}
+
+	if expr.Op == filter.OpNe {
+		return fmt.Sprintf("(%s <> ? OR %s IS NULL)", key, key),
+			[]interface{}{expr.Value}
+	}
+
+	// missing values compare false instead of NULL
+	clause := fmt.Sprintf("(%s %s ? AND %s IS NOT NULL)",
+		key, sqlOperators[expr.Op], key)
+	return clause, []interface{}{expr.Value}
+}
+
+func compileContinue(
+	token *store.ContinueToken,
+	path func(string) string) (string, []interface{}) {
+
+	clauses := []string{}
+	args := []interface{}{}
+
+	equal := []string{}
+	for i, o := range token.Order {
+		key := path(o.Key)
+		val := token.Values[i]
+
+		after := ""
+		afterArgs := []interface{}{}
+		switch {
+		case val == nil && !o.Descending:
+			after = fmt.Sprintf("%s IS NOT NULL", key)
+		case val == nil:
+		case o.Descending:
+			after = fmt.Sprintf("(%s < ? OR %s IS NULL)", key, key)
+			afterArgs = append(afterArgs, val)
+		default:
+			after = fmt.Sprintf("%s > ?", key)
+			afterArgs = append(afterArgs, val)
+		}
+
+		if len(after) > 0 {
+			clauses = append(clauses,
+				"("+strings.Join(append(append([]string{}, equal...), after), " AND ")+")")
+			args = append(args, equalArgs(token.Values[:i])...)
+			args = append(args, afterArgs...)
+		}
+
+		if val == nil {
+			equal = append(equal, fmt.Sprintf("%s IS NULL", key))
+		} else {
+			equal = append(equal, fmt.Sprintf("%s = ?", key))
+		}
+	}
+
+	pkey := "Pkey > ?"
+	if token.KeyDescending() {
+		pkey = "Pkey < ?"
+	}
+
+	clauses = append(clauses,
+		"("+strings.Join(append(equal, pkey), " AND ")+")")
+	args = append(args, equalArgs(token.Values)...)
+	args = append(args, token.Key)
+
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// missing values are matched with IS NULL and take no argument
+func equalArgs(values []interface{}) []interface{} {
+	res := []interface{}{}
+	for _, v := range values {
+		if v != nil {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+func compileOrder(copt options.CommonOptionHolder, path func(string) string) string {
+	keys := []string{}
+	direction := "ASC"
+	if copt.Descending {
+		direction = "DESC"
+	}
+	for _, o := range copt.Order {
+		direction = "ASC"
+		if o.Descending {
+			direction = "DESC"
+		}
+		keys = append(keys, fmt.Sprintf("%s %s", path(o.Key), direction))
+	}
+
+	keys = append(keys, "Pkey "+direction)
+
+	return " ORDER BY " + strings.Join(keys, ", ")
+}
+
+// indexKey is the indexed expression of the n-th key, mysql can only
+// index scalar expressions and queries have to repeat them to use the index,
+// unique indexes are limited to the objects of the kind
+func indexKey(driver string, kind string, index store.Index, n int) string {
+	key := jsonPath(index.Keys[n])
+	if driver != "mysql" {
+		return key
+	}
+
+	tp := ""
+	if n < len(index.Types) {
+		tp = index.Types[n]
+	}
+
+	switch tp {
+	case "number":
+		key = fmt.Sprintf("CAST(%s AS DOUBLE)", key)
+	case "bool":
+		key = fmt.Sprintf("CAST(%s AS UNSIGNED)", key)
+	default:
+		key = fmt.Sprintf("CAST(json_unquote(%s) AS CHAR(255))", key)
+	}
+
+	if index.Unique {
+		key = fmt.Sprintf("CASE WHEN Type = '%s' THEN %s END",
+			strings.ToLower(kind), key)
+	}
+
+	return key
+}
+
+// mysql has no IF NOT EXISTS for indexes
+func compileIndex(driver string, kind string, index store.Index) string {
+	typ := strings.ToLower(kind)
+	keys := []string{"Type"}
+	for n := range index.Keys {
+		key := indexKey(driver, kind, index, n)
+		if driver == "mysql" {
+			key = "(" + key + ")"
+		}
+		keys = append(keys, key)
+	}
+
+	if driver == "mysql" {
+		unique := ""
+		if index.Unique {
+			unique = "UNIQUE "
+		}
+
+		return fmt.Sprintf("CREATE %sINDEX %s ON Objects (%s)",
+			unique, index.Name, strings.Join(keys, ", "))
+	}
+
+	if index.Unique {
+		return fmt.Sprintf(
+			"CREATE UNIQUE INDEX IF NOT EXISTS %s ON Objects (%s) WHERE Type = '%s'",
+			index.Name, strings.Join(keys, ", "), typ)
+	}
+
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON Objects (%s)",
+		index.Name, strings.Join(keys, ", "))
+}
+
+// mysql error 1061
+func isDuplicateIndex(err error) bool {
+	return strings.Contains(err.Error(), "Duplicate key name")
+}
+
+// only secondary indexes count, primary key collisions
+// are caught by the existence checks
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed: index") ||
+		(strings.Contains(msg, "Duplicate entry") && !strings.Contains(msg, "PRIMARY"))
+}
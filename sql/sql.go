@@ -3,6 +3,7 @@ package sql
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/wazofski/storz/store"
 	"github.com/wazofski/storz/store/options"
 	"github.com/wazofski/storz/utils"
+	"golang.org/x/exp/slices"
 
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/mattn/go-sqlite3"
@@ -20,14 +22,23 @@ var log = logger.Factory("sql")
 
 type _ConnectionMaker func(*sqlStore) (*sql.DB, error)
 
+type _Executor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 type sqlStore struct {
 	Schema         store.SchemaHolder
 	DB             *sql.DB
+	Tx             *sql.Tx
+	Driver         string
 	MakeConnection _ConnectionMaker
 }
 
 func SqliteConnection(path string) _ConnectionMaker {
 	return func(d *sqlStore) (*sql.DB, error) {
+		d.Driver = "sqlite3"
 		return sql.Open("sqlite3", path)
 	}
 }
@@ -37,6 +48,7 @@ func MySqlConnection(path string) _ConnectionMaker {
 		log.Printf("mysql connection %s", path)
 		// username:password@tcp(127.0.0.1:3306)/test
 
+		d.Driver = "mysql"
 		return sql.Open("mysql", path)
 	}
 }
@@ -106,20 +118,29 @@ func (d *sqlStore) Create(
 		return nil, err
 	}
 
-	err = d.setIdentity(
-		obj.Metadata().Identity().Path(),
-		obj.PrimaryKey(),
-		obj.Metadata().Kind())
+	clone, err := utils.CopyObject(obj, d.Schema)
+	if err != nil {
+		return nil, err
+	}
+	if clone.Metadata().Revision() < 1 {
+		clone.Metadata().(store.MetaSetter).SetRevision(1)
+	}
+
+	// unique indexes reject the object before the identity is stored
+	err = d.setObject(clone.PrimaryKey(), clone.Metadata().Kind(), clone)
 	if err != nil {
 		return nil, err
 	}
 
-	err = d.setObject(obj.PrimaryKey(), obj.Metadata().Kind(), obj)
+	err = d.setIdentity(
+		clone.Metadata().Identity().Path(),
+		clone.PrimaryKey(),
+		clone.Metadata().Kind())
 	if err != nil {
 		return nil, err
 	}
 
-	return obj.Clone(), nil
+	return clone.Clone(), nil
 }
 
 func (d *sqlStore) Update(
@@ -148,6 +169,10 @@ func (d *sqlStore) Update(
 		return nil, constants.ErrNoSuchObject
 	}
 
+	if copt.IfRevision > 0 && copt.IfRevision != existing.Metadata().Revision() {
+		return nil, constants.ErrConflict
+	}
+
 	err = d.TestConnection()
 	if err != nil {
 		return nil, err
@@ -155,29 +180,31 @@ func (d *sqlStore) Update(
 
 	// log.Object("existing", existing)
 
-	err = d.removeIdentity(existing.Metadata().Identity().Path())
+	clone, err := utils.CopyObject(obj, d.Schema)
 	if err != nil {
-		log.Printf("%s", err)
+		return nil, err
 	}
+	clone.Metadata().(store.MetaSetter).SetRevision(
+		existing.Metadata().Revision() + 1)
 
-	err = d.setIdentity(obj.Metadata().Identity().Path(),
-		obj.PrimaryKey(), obj.Metadata().Kind())
-
+	err = d.replaceRevision(existing, clone)
 	if err != nil {
 		return nil, err
 	}
 
-	err = d.removeObject(existing.PrimaryKey(), existing.Metadata().Kind())
+	err = d.removeIdentity(existing.Metadata().Identity().Path())
 	if err != nil {
-		return nil, err
+		log.Printf("%s", err)
 	}
 
-	err = d.setObject(obj.PrimaryKey(), obj.Metadata().Kind(), obj)
+	err = d.setIdentity(clone.Metadata().Identity().Path(),
+		clone.PrimaryKey(), clone.Metadata().Kind())
+
 	if err != nil {
 		return nil, err
 	}
 
-	return obj.Clone(), nil
+	return clone.Clone(), nil
 }
 
 func (d *sqlStore) Delete(
@@ -201,17 +228,21 @@ func (d *sqlStore) Delete(
 		return constants.ErrNoSuchObject
 	}
 
+	if copt.IfRevision > 0 && copt.IfRevision != existing.Metadata().Revision() {
+		return constants.ErrConflict
+	}
+
 	err = d.TestConnection()
 	if err != nil {
 		return err
 	}
 
-	err = d.removeIdentity(existing.Metadata().Identity().Path())
+	err = d.removeRevision(existing)
 	if err != nil {
 		return err
 	}
 
-	return d.removeObject(existing.PrimaryKey(), existing.Metadata().Kind())
+	return d.removeIdentity(existing.Metadata().Identity().Path())
 }
 
 func (d *sqlStore) Get(
@@ -235,17 +266,30 @@ func (d *sqlStore) Get(
 		return nil, err
 	}
 
+	var ret store.Object
 	pkey, typ, err := d.getIdentity(identity.Path())
 	if err == nil {
-		return d.getObject(pkey, typ)
+		ret, err = d.getObject(pkey, typ)
+	} else {
+		tokens := strings.Split(identity.Path(), "/")
+		if len(tokens) != 2 {
+			return nil, constants.ErrNoSuchObject
+		}
+		ret, err = d.getObject(tokens[1], tokens[0])
+	}
+
+	if err != nil {
+		return nil, err
 	}
 
-	tokens := strings.Split(identity.Path(), "/")
-	if len(tokens) == 2 {
-		return d.getObject(tokens[1], tokens[0])
+	if copt.Fields != nil {
+		err = utils.ValidateFields(d.Schema, ret.Metadata().Kind(), copt.Fields)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return nil, constants.ErrNoSuchObject
+	return utils.ProjectObject(ret, d.Schema, copt.Fields)
 }
 
 func (d *sqlStore) List(
@@ -253,10 +297,23 @@ func (d *sqlStore) List(
 	identity store.ObjectIdentity,
 	opt ...options.ListOption) (store.ObjectList, error) {
 
+	page, err := d.ListPage(ctx, identity, opt...)
+	if err != nil {
+		return nil, err
+	}
+
+	return page.Objects, nil
+}
+
+func (d *sqlStore) ListPage(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (store.Page, error) {
+
 	log.Printf("list %s", identity)
 
 	if len(identity.Key()) > 0 {
-		return nil, constants.ErrInvalidPath
+		return store.Page{}, constants.ErrInvalidPath
 	}
 
 	var err error
@@ -264,53 +321,39 @@ func (d *sqlStore) List(
 	for _, o := range opt {
 		err = o.ApplyFunction()(&copt)
 		if err != nil {
-			return nil, err
+			return store.Page{}, err
 		}
 	}
 
-	err = d.TestConnection()
+	token, err := store.ContinueFrom(copt)
 	if err != nil {
-		return nil, err
+		return store.Page{}, err
 	}
 
-	query := `SELECT Object FROM Objects
-		WHERE Type = ?`
-
-	// pkey filter
-	if copt.KeyFilter != nil {
-		query = query + fmt.Sprintf(
-			" AND Pkey IN ('%s')",
-			strings.Join(*copt.KeyFilter, "', '"))
+	err = d.TestConnection()
+	if err != nil {
+		return store.Page{}, err
 	}
 
-	// prop filter
-	if copt.PropFilter != nil {
-		obj := d.Schema.ObjectForKind(identity.Type())
-		if obj == nil {
-			return nil, constants.ErrNoSuchObject
-		}
-		if utils.ObjectPath(obj, copt.PropFilter.Key) == nil {
-			return nil, constants.ErrInvalidFilter
-		}
-
-		query = query + fmt.Sprintf(
-			" AND json_extract(Object, '$.%s') = '%s'",
-			copt.PropFilter.Key, copt.PropFilter.Value)
+	selection := "SELECT Object"
+	if copt.Fields != nil {
+		selection = "SELECT " + compileFields(copt.Fields, copt.Order)
 	}
 
-	if len(copt.OrderBy) > 0 {
-		query = fmt.Sprintf(`SELECT Object
-			FROM Objects
-			WHERE Type = ?
-			ORDER BY json_extract(Object, '$.%s')`, copt.OrderBy)
+	query, args, err := d.listQuery(selection, identity, copt)
+	if err != nil {
+		return store.Page{}, err
+	}
 
-		if copt.OrderIncremental {
-			query = query + " ASC"
-		} else {
-			query = query + " DESC"
-		}
+	// keyset pagination
+	if token != nil {
+		clause, kargs := compileContinue(token, d.keyPath(identity.Type()))
+		query = query + " AND " + clause
+		args = append(args, kargs...)
 	}
 
+	query = query + compileOrder(copt, d.keyPath(identity.Type()))
+
 	if copt.PageSize > 0 {
 		query = query + fmt.Sprintf(" LIMIT %d", copt.PageSize)
 	}
@@ -321,15 +364,134 @@ func (d *sqlStore) List(
 
 	log.Printf(query)
 
-	rows, err := d.DB.Query(query, identity.Type())
+	rows, err := d.conn().Query(query, args...)
 	if err != nil {
-		return nil, err
+		return store.Page{}, err
 	}
+	defer rows.Close()
 
-	res := d.parseObjectRows(rows, identity.Type())
-	rows.Close()
+	if copt.Fields != nil {
+		return d.parseProjectionRows(rows, identity.Type(), copt)
+	}
 
-	return res, nil
+	page := store.Page{
+		Objects: d.parseObjectRows(rows, identity.Type()),
+	}
+
+	if store.Full(len(page.Objects), copt) {
+		page.Continue = store.NextToken(page.Objects[len(page.Objects)-1], copt)
+	}
+
+	return page, nil
+}
+
+func (d *sqlStore) Count(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (int, error) {
+
+	log.Printf("count %s", identity)
+
+	if len(identity.Key()) > 0 {
+		return 0, constants.ErrInvalidPath
+	}
+
+	var err error
+	copt := options.CommonOptionHolderFactory()
+	for _, o := range opt {
+		err = o.ApplyFunction()(&copt)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = d.TestConnection()
+	if err != nil {
+		return 0, err
+	}
+
+	query, args, err := d.listQuery("SELECT COUNT(*)", identity, copt)
+	if err != nil {
+		return 0, err
+	}
+
+	res := 0
+	err = d.conn().QueryRow(query, args...).Scan(&res)
+
+	return res, err
+}
+
+// listQuery builds the filtering part of list queries
+func (d *sqlStore) listQuery(
+	selection string,
+	identity store.ObjectIdentity,
+	copt options.CommonOptionHolder) (string, []interface{}, error) {
+
+	query := selection + ` FROM Objects
+		WHERE Type = ?`
+	args := []interface{}{identity.Type()}
+
+	err := utils.ValidateOptions(d.Schema, identity.Type(), copt)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// pkey filter
+	if copt.KeyFilter != nil {
+		marks := strings.TrimSuffix(
+			strings.Repeat("?, ", len(*copt.KeyFilter)), ", ")
+		query = query + fmt.Sprintf(" AND Pkey IN (%s)", marks)
+		for _, k := range *copt.KeyFilter {
+			args = append(args, k)
+		}
+	}
+
+	// prop filter
+	if copt.PropFilter != nil {
+		query = query + fmt.Sprintf(
+			" AND %s = ?", d.keyPath(identity.Type())(copt.PropFilter.Key))
+		args = append(args, copt.PropFilter.Value)
+	}
+
+	// filter expression
+	if copt.Filter != nil {
+		clause, fargs := compileFilter(copt.Filter, d.keyPath(identity.Type()))
+		query = query + " AND " + clause
+		args = append(args, fargs...)
+	}
+
+	// label selector
+	for k, v := range copt.Labels {
+		query = query + " AND json_extract(Object, ?) = ?"
+		args = append(args, labelPath(k), v)
+	}
+
+	return query, args, nil
+}
+
+// keyPath gives the expression a property is compared and ordered by,
+// the indexed one when an index covers it
+func (d *sqlStore) keyPath(kind string) func(string) string {
+	indexes := store.Indexes(d.Schema, kind)
+	return func(key string) string {
+		for _, i := range indexes {
+			n := slices.Index(i.Keys, key)
+			if n >= 0 {
+				return indexKey(d.Driver, kind, i, n)
+			}
+		}
+
+		return jsonPath(key)
+	}
+}
+
+// statements run inside the batch transaction when there is one
+func (d *sqlStore) conn() _Executor {
+	if d.Tx != nil {
+		return d.Tx
+	}
+
+	return d.DB
 }
 
 func (d *sqlStore) prepareTables() error {
@@ -341,7 +503,7 @@ func (d *sqlStore) prepareTables() error {
 		Pkey NVARCHAR(50) NOT NULL,
 		Type VARCHAR(25) NOT NULL);`
 
-	_, err := d.DB.Exec(create)
+	_, err := d.conn().Exec(create)
 	if err != nil {
 		return err
 	}
@@ -353,16 +515,41 @@ func (d *sqlStore) prepareTables() error {
 		Object JSON,
 		PRIMARY KEY (Pkey,Type));`
 
-	_, err = d.DB.Exec(create)
+	_, err = d.conn().Exec(create)
 	if err != nil {
 		return err
 	}
 
+	return d.prepareIndexes()
+}
+
+// secondary indexes are expression indexes over the Objects table
+// prefixed by the object type
+func (d *sqlStore) prepareIndexes() error {
+	for _, kind := range d.Schema.Types() {
+		for _, i := range store.Indexes(d.Schema, kind) {
+			err := store.ValidateIndexName(i.Name)
+			if err != nil {
+				return err
+			}
+
+			err = utils.ValidateFields(d.Schema, kind, i.Keys)
+			if err != nil {
+				return fmt.Errorf("index %s: %w", i.Name, err)
+			}
+
+			_, err = d.conn().Exec(compileIndex(d.Driver, kind, i))
+			if err != nil && !isDuplicateIndex(err) {
+				return fmt.Errorf("index %s: %w", i.Name, err)
+			}
+		}
+	}
+
 	return nil
 }
 
 func (d *sqlStore) getIdentity(path string) (string, string, error) {
-	row := d.DB.QueryRow("SELECT Pkey, Type FROM IdIndex WHERE Path=?", path)
+	row := d.conn().QueryRow("SELECT Pkey, Type FROM IdIndex WHERE Path=?", path)
 
 	var pkey string = ""
 	var typ string = ""
@@ -383,7 +570,7 @@ func (d *sqlStore) setIdentity(path string, pkey string, typ string) error {
 		query = `insert into IdIndex (Pkey, Type, Path) values (?, ?, ?)`
 	}
 
-	_, err = d.DB.Exec(query, pkey, strings.ToLower(typ), path)
+	_, err = d.conn().Exec(query, pkey, strings.ToLower(typ), path)
 
 	return err
 }
@@ -391,7 +578,7 @@ func (d *sqlStore) setIdentity(path string, pkey string, typ string) error {
 func (d *sqlStore) removeIdentity(path string) error {
 	query := "DELETE FROM IdIndex WHERE Path = ?"
 
-	_, err := d.DB.Exec(query, path)
+	_, err := d.conn().Exec(query, path)
 	return err
 }
 
@@ -399,7 +586,7 @@ func (d *sqlStore) getObject(pkey string, typ string) (store.Object, error) {
 	// log.Printf("getting %s %s", pkey, typ)
 
 	return d.parseObjectRow(
-		d.DB.QueryRow("SELECT Object FROM Objects WHERE Pkey=? AND Type=?",
+		d.conn().QueryRow("SELECT Object FROM Objects WHERE Pkey=? AND Type=?",
 			pkey, strings.ToLower(typ)), typ)
 }
 
@@ -418,16 +605,73 @@ func (d *sqlStore) setObject(pkey string, typ string, obj store.Object) error {
 		return err
 	}
 
-	_, err = d.DB.Exec(query, string(data), pkey, strings.ToLower(typ))
+	_, err = d.conn().Exec(query, string(data), pkey, strings.ToLower(typ))
+	if isUniqueViolation(err) {
+		return constants.ErrUniqueViolation
+	}
+
 	return err
 }
 
-func (d *sqlStore) removeObject(pkey string, typ string) error {
-	query := "DELETE FROM Objects WHERE Pkey = ? AND Type = ?"
+// replaceRevision stores the object in place of the existing revision in one
+// statement, it fails when a concurrent update got there first or a unique
+// index rejects the object and the existing revision is then left as it was
+func (d *sqlStore) replaceRevision(existing store.Object, obj store.Object) error {
+	query := `UPDATE Objects SET Object = ?, Pkey = ? WHERE Pkey = ? AND Type = ?
+		AND COALESCE(json_extract(Object, '$.metadata.revision'), 0) = ?`
 
-	_, err := d.DB.Exec(query, pkey, strings.ToLower(typ))
+	data, err := utils.Serialize(obj)
+	if err != nil {
+		return err
+	}
 
-	return err
+	res, err := d.conn().Exec(query,
+		string(data),
+		obj.PrimaryKey(),
+		existing.PrimaryKey(),
+		strings.ToLower(existing.Metadata().Kind()),
+		existing.Metadata().Revision())
+	if isUniqueViolation(err) {
+		return constants.ErrUniqueViolation
+	}
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return constants.ErrConflict
+	}
+
+	return nil
+}
+
+func (d *sqlStore) removeRevision(existing store.Object) error {
+	query := `DELETE FROM Objects WHERE Pkey = ? AND Type = ?
+		AND COALESCE(json_extract(Object, '$.metadata.revision'), 0) = ?`
+
+	res, err := d.conn().Exec(query,
+		existing.PrimaryKey(),
+		strings.ToLower(existing.Metadata().Kind()),
+		existing.Metadata().Revision())
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return constants.ErrConflict
+	}
+
+	return nil
 }
 
 func (d *sqlStore) parseObjectRow(row *sql.Row, typ string) (store.Object, error) {
@@ -465,3 +709,50 @@ func (d *sqlStore) parseObjectRows(rows *sql.Rows, typ string) store.ObjectList
 
 	return res
 }
+
+func (d *sqlStore) parseProjectionRows(
+	rows *sql.Rows,
+	typ string,
+	copt options.CommonOptionHolder) (store.Page, error) {
+
+	res := store.Page{
+		Objects: store.ObjectList{},
+	}
+
+	var values []interface{}
+	for rows.Next() {
+		var data string = ""
+		err := rows.Scan(&data)
+		if err != nil {
+			return store.Page{}, err
+		}
+
+		values = []interface{}{}
+		err = json.Unmarshal([]byte(data), &values)
+		if err != nil {
+			return store.Page{}, err
+		}
+
+		doc := make(map[string]interface{})
+		for i, f := range copt.Fields {
+			if values[i] != nil {
+				utils.SetDocumentPath(doc, f, values[i])
+			}
+		}
+
+		ret, err := utils.UnmarshalProjection(doc, d.Schema, typ, copt.Fields)
+		if err != nil {
+			return store.Page{}, err
+		}
+
+		res.Objects = append(res.Objects, ret)
+	}
+
+	if store.Full(len(res.Objects), copt) {
+		last := values[len(copt.Fields):]
+		res.Continue = store.TokenOf(copt,
+			last[:len(copt.Order)], fmt.Sprint(last[len(copt.Order)]))
+	}
+
+	return res, nil
+}
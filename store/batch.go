@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/store/options"
+)
+
+type OpType string
+
+const (
+	OpCreate OpType = "create"
+	OpUpdate OpType = "update"
+	OpDelete OpType = "delete"
+)
+
+// Op describes a single write in a batch.
+// Identity is used by updates and deletes, Object by creates and updates.
+// A non zero IfRevision makes the write conditional like options.IfRevision.
+type Op struct {
+	Type       OpType
+	Identity   ObjectIdentity
+	Object     Object
+	IfRevision int64
+}
+
+// Transactional is implemented by stores able to apply
+// several writes atomically: either all ops succeed or none is applied.
+// The result holds one entry per op, nil for deletes.
+type Transactional interface {
+	Batch(context.Context, []Op) (ObjectList, error)
+}
+
+func Batch(
+	ctx context.Context,
+	st Store,
+	ops []Op) (ObjectList, error) {
+
+	transactional, ok := st.(Transactional)
+	if !ok {
+		return nil, constants.ErrUnsupported
+	}
+
+	return transactional.Batch(ctx, ops)
+}
+
+func CreateOp(obj Object) Op {
+	return Op{
+		Type:   OpCreate,
+		Object: obj,
+	}
+}
+
+func UpdateOp(identity ObjectIdentity, obj Object) Op {
+	return Op{
+		Type:     OpUpdate,
+		Identity: identity,
+		Object:   obj,
+	}
+}
+
+func DeleteOp(identity ObjectIdentity) Op {
+	return Op{
+		Type:     OpDelete,
+		Identity: identity,
+	}
+}
+
+// Validate checks the op is well formed before any of the batch is applied
+func (o Op) Validate() error {
+	switch o.Type {
+	case OpCreate:
+		if o.Object == nil {
+			return constants.ErrObjectNil
+		}
+	case OpUpdate:
+		if o.Object == nil {
+			return constants.ErrObjectNil
+		}
+		if len(o.Identity) == 0 {
+			return constants.ErrInvalidPath
+		}
+	case OpDelete:
+		if len(o.Identity) == 0 {
+			return constants.ErrInvalidPath
+		}
+	default:
+		return constants.ErrInvalidMethod
+	}
+
+	return nil
+}
+
+func (o Op) UpdateOptions() []options.UpdateOption {
+	if o.IfRevision == 0 {
+		return nil
+	}
+
+	return []options.UpdateOption{options.IfRevision(o.IfRevision)}
+}
+
+func (o Op) DeleteOptions() []options.DeleteOption {
+	if o.IfRevision == 0 {
+		return nil
+	}
+
+	return []options.DeleteOption{options.IfRevision(o.IfRevision)}
+}
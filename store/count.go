@@ -0,0 +1,28 @@
+package store
+
+import (
+	"context"
+
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/store/options"
+)
+
+// Counter is implemented by stores able to count the objects
+// a List call would match, pagination options are ignored
+type Counter interface {
+	Count(context.Context, ObjectIdentity, ...options.ListOption) (int, error)
+}
+
+func Count(
+	ctx context.Context,
+	st Store,
+	identity ObjectIdentity,
+	opt ...options.ListOption) (int, error) {
+
+	counter, ok := st.(Counter)
+	if !ok {
+		return 0, constants.ErrUnsupported
+	}
+
+	return counter.Count(ctx, identity, opt...)
+}
@@ -0,0 +1,20 @@
+package store
+
+// Enum is implemented by the enum types of the model
+type Enum interface {
+	Values() []string
+}
+
+// EnumHolder is implemented by schemas declaring enum types
+type EnumHolder interface {
+	Enums() map[string]Enum
+}
+
+func Enums(schema SchemaHolder) map[string]Enum {
+	holder, ok := schema.(EnumHolder)
+	if !ok {
+		return nil
+	}
+
+	return holder.Enums()
+}
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Index is a secondary index over property paths of the objects of a kind,
+// Types hold the JSON type of every key, string, number or bool,
+// for stores indexing typed expressions
+type Index struct {
+	Name   string
+	Keys   []string
+	Types  []string
+	Unique bool
+}
+
+// IndexHolder is implemented by schemas declaring secondary indexes
+type IndexHolder interface {
+	Indexes(kind string) []Index
+}
+
+func Indexes(schema SchemaHolder, kind string) []Index {
+	holder, ok := schema.(IndexHolder)
+	if !ok {
+		return nil
+	}
+
+	return holder.Indexes(kind)
+}
+
+var indexName = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*$")
+
+// ValidateIndexName makes sure the name can be used as an SQL identifier
+func ValidateIndexName(name string) error {
+	if !indexName.MatchString(name) {
+		return fmt.Errorf("invalid index name %q", name)
+	}
+
+	return nil
+}
+
+// IndexName derives an index name from the kind and the keys
+func IndexName(kind string, keys []string) string {
+	name := fmt.Sprintf("%s_%s", kind, strings.Join(keys, "_"))
+	return "idx_" + strings.ToLower(strings.ReplaceAll(name, ".", "_"))
+}
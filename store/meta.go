@@ -5,6 +5,9 @@ type Meta interface {
 	Identity() ObjectIdentity
 	Created() string
 	Updated() string
+	Revision() int64
+	Labels() map[string]string
+	Annotations() map[string]string
 }
 
 type MetaSetter interface {
@@ -12,6 +15,9 @@ type MetaSetter interface {
 	SetIdentity(ObjectIdentity)
 	SetCreated(string)
 	SetUpdated(string)
+	SetRevision(int64)
+	SetLabels(map[string]string)
+	SetAnnotations(map[string]string)
 }
 
 type MetaHolder interface {
@@ -23,6 +29,10 @@ type metaWrapper struct {
 	Identity_ *ObjectIdentity `json:"identity"`
 	Created_  *string         `json:"created"`
 	Updated_  *string         `json:"updated"`
+	Revision_ *int64          `json:"revision"`
+
+	Labels_      map[string]string `json:"labels,omitempty"`
+	Annotations_ map[string]string `json:"annotations,omitempty"`
 }
 
 func (m *metaWrapper) Kind() string {
@@ -41,6 +51,20 @@ func (m *metaWrapper) Identity() ObjectIdentity {
 	return *m.Identity_
 }
 
+func (m *metaWrapper) Revision() int64 {
+	return *m.Revision_
+}
+
+// Labels are the key value pairs objects are selected by
+func (m *metaWrapper) Labels() map[string]string {
+	return m.Labels_
+}
+
+// Annotations are free form key value pairs not used for selection
+func (m *metaWrapper) Annotations() map[string]string {
+	return m.Annotations_
+}
+
 func (m *metaWrapper) SetKind(kind string) {
 	m.Kind_ = &kind
 }
@@ -57,15 +81,42 @@ func (m *metaWrapper) SetUpdated(updated string) {
 	m.Updated_ = &updated
 }
 
+func (m *metaWrapper) SetRevision(revision int64) {
+	m.Revision_ = &revision
+}
+
+func (m *metaWrapper) SetLabels(labels map[string]string) {
+	m.Labels_ = labels
+}
+
+func (m *metaWrapper) SetAnnotations(annotations map[string]string) {
+	m.Annotations_ = annotations
+}
+
+// HasLabels tells whether the object carries every given label
+func HasLabels(obj Object, labels map[string]string) bool {
+	own := obj.Metadata().Labels()
+	for k, v := range labels {
+		val, ok := own[k]
+		if !ok || val != v {
+			return false
+		}
+	}
+
+	return true
+}
+
 func MetaFactory(kind string) Meta {
 	emptyIdentity := ObjectIdentityFactory()
 	emptyString1 := ""
 	emptyString2 := ""
+	emptyRevision := int64(0)
 	mw := metaWrapper{
 		Kind_:     &kind,
 		Identity_: &emptyIdentity,
 		Created_:  &emptyString1,
 		Updated_:  &emptyString2,
+		Revision_: &emptyRevision,
 	}
 
 	return &mw
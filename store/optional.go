@@ -0,0 +1,16 @@
+package store
+
+// OptionalHolder is implemented by schemas declaring optional properties,
+// their paths are missing from the objects they are not set in
+type OptionalHolder interface {
+	Optionals(kind string) []string
+}
+
+func Optionals(schema SchemaHolder, kind string) []string {
+	holder, ok := schema.(OptionalHolder)
+	if !ok {
+		return nil
+	}
+
+	return holder.Optionals(kind)
+}
@@ -2,7 +2,11 @@ package options
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"strings"
+
+	"github.com/wazofski/storz/filter"
 )
 
 type Option interface {
@@ -34,6 +38,29 @@ type ListOption interface {
 	GetListOption() Option
 }
 
+type WatchOption interface {
+	Option
+	GetWatchOption() Option
+}
+
+// FilterOption narrows down both listed and watched objects
+type FilterOption interface {
+	ListOption
+	WatchOption
+}
+
+// FieldsOption trims both fetched and listed objects
+type FieldsOption interface {
+	GetOption
+	ListOption
+}
+
+// PreconditionOption guards modifications of existing objects
+type PreconditionOption interface {
+	UpdateOption
+	DeleteOption
+}
+
 type OptionHolder interface {
 	CommonOptions() *CommonOptionHolder
 }
@@ -47,13 +74,24 @@ type PropFilterSetting struct {
 
 type KeyFilterSetting []string
 
+// OrderSetting is one key of a list order
+type OrderSetting struct {
+	Key        string `json:"k"`
+	Descending bool   `json:"d,omitempty"`
+}
+
 type CommonOptionHolder struct {
-	PropFilter       *PropFilterSetting
-	KeyFilter        *KeyFilterSetting
-	OrderBy          string
-	OrderIncremental bool
-	PageSize         int
-	PageOffset       int
+	PropFilter *PropFilterSetting
+	Filter     *filter.Expression
+	KeyFilter  *KeyFilterSetting
+	Order      []OrderSetting
+	Descending bool
+	PageSize   int
+	PageOffset int
+	Continue   string
+	Fields     []string
+	IfRevision int64
+	Labels     map[string]string
 }
 
 func (d *CommonOptionHolder) CommonOptions() *CommonOptionHolder {
@@ -62,17 +100,22 @@ func (d *CommonOptionHolder) CommonOptions() *CommonOptionHolder {
 
 func CommonOptionHolderFactory() CommonOptionHolder {
 	return CommonOptionHolder{
-		PropFilter:       nil,
-		KeyFilter:        nil,
-		OrderBy:          "",
-		OrderIncremental: true,
-		PageSize:         0,
-		PageOffset:       0,
+		PropFilter: nil,
+		Filter:     nil,
+		KeyFilter:  nil,
+		Order:      nil,
+		Descending: false,
+		PageSize:   0,
+		PageOffset: 0,
+		Continue:   "",
+		Fields:     nil,
+		IfRevision: 0,
+		Labels:     nil,
 	}
 }
 
-func PropFilter(prop string, val string) ListOption {
-	return listOption{
+func PropFilter(prop string, val string) FilterOption {
+	return filterOption{
 		Function: func(options OptionHolder) error {
 			commonOptions := options.CommonOptions()
 			if commonOptions.PropFilter != nil {
@@ -91,8 +134,27 @@ func PropFilter(prop string, val string) ListOption {
 	}
 }
 
-func KeyFilter(keys ...string) ListOption {
-	return listOption{
+func Filter(expr *filter.Expression) FilterOption {
+	return filterOption{
+		Function: func(options OptionHolder) error {
+			commonOptions := options.CommonOptions()
+			if commonOptions.Filter != nil {
+				return errors.New("filter option already set")
+			}
+
+			err := expr.Validate()
+			if err != nil {
+				return err
+			}
+
+			commonOptions.Filter = expr
+			return nil
+		},
+	}
+}
+
+func KeyFilter(keys ...string) FilterOption {
+	return filterOption{
 		Function: func(options OptionHolder) error {
 			if len(keys) == 0 {
 				log.Printf("ignoring empty key filter")
@@ -144,29 +206,119 @@ func PageOffset(po int) ListOption {
 	}
 }
 
+// Continue resumes listing after the last object of a previous page
+// using the token returned along with that page
+func Continue(token string) ListOption {
+	return listOption{
+		Function: func(options OptionHolder) error {
+			commonOptions := options.CommonOptions()
+			if len(commonOptions.Continue) > 0 {
+				return errors.New("continue option has already been set")
+			}
+			commonOptions.Continue = token
+			return nil
+		},
+	}
+}
+
+// OrderBy adds an ascending key to the list order,
+// keys given first take precedence
 func OrderBy(field string) ListOption {
 	return listOption{
 		Function: func(options OptionHolder) error {
 			commonOptions := options.CommonOptions()
-			if len(commonOptions.OrderBy) > 0 {
-				return errors.New("order by option has already been set")
+			if commonOptions.Descending {
+				return errors.New("order by option has to precede order descending")
+			}
+			for _, o := range commonOptions.Order {
+				if o.Key == field {
+					return errors.New("order by option has already been set")
+				}
 			}
-			commonOptions.OrderBy = field
+			commonOptions.Order = append(commonOptions.Order,
+				OrderSetting{Key: field})
 			// log.Printf("order by option: %s", field)
 			return nil
 		},
 	}
 }
 
+// OrderDescending reverses the direction of the preceding OrderBy key,
+// of the primary key order when there is none
 func OrderDescending() ListOption {
 	return listOption{
 		Function: func(options OptionHolder) error {
 			commonOptions := options.CommonOptions()
-			if !commonOptions.OrderIncremental {
+			if len(commonOptions.Order) == 0 {
+				if commonOptions.Descending {
+					return errors.New("order incremental option has already been set")
+				}
+				commonOptions.Descending = true
+				return nil
+			}
+			last := &commonOptions.Order[len(commonOptions.Order)-1]
+			if last.Descending {
 				return errors.New("order incremental option has already been set")
 			}
-			commonOptions.OrderIncremental = false
-			// log.Printf("order incremental option %v", val)
+			last.Descending = true
+			return nil
+		},
+	}
+}
+
+// Fields selects the property paths returned objects are trimmed to,
+// the remaining properties keep their default values
+func Fields(paths ...string) FieldsOption {
+	return fieldsOption{
+		Function: func(options OptionHolder) error {
+			commonOptions := options.CommonOptions()
+			if commonOptions.Fields != nil {
+				return errors.New("fields option has already been set")
+			}
+			if len(paths) == 0 {
+				return errors.New("fields option needs at least one path")
+			}
+			commonOptions.Fields = paths
+			return nil
+		},
+	}
+}
+
+// LabelSelector lists only the objects carrying every given label,
+// label keys cannot contain quotes or backslashes
+func LabelSelector(labels map[string]string) ListOption {
+	return listOption{
+		Function: func(options OptionHolder) error {
+			commonOptions := options.CommonOptions()
+			if commonOptions.Labels != nil {
+				return errors.New("label selector option has already been set")
+			}
+			if len(labels) == 0 {
+				return errors.New("label selector needs at least one label")
+			}
+			for k := range labels {
+				if strings.ContainsAny(k, `"\`) {
+					return fmt.Errorf(
+						"label key %s cannot contain quotes or backslashes", k)
+				}
+			}
+			commonOptions.Labels = labels
+			return nil
+		},
+	}
+}
+
+func IfRevision(rev int64) PreconditionOption {
+	return preconditionOption{
+		Function: func(options OptionHolder) error {
+			commonOptions := options.CommonOptions()
+			if commonOptions.IfRevision > 0 {
+				return errors.New("revision precondition has already been set")
+			}
+			if rev <= 0 {
+				return errors.New("revision must be positive")
+			}
+			commonOptions.IfRevision = rev
 			return nil
 		},
 	}
@@ -183,3 +335,51 @@ func (d listOption) GetListOption() Option {
 func (d listOption) ApplyFunction() OptionFunction {
 	return d.Function
 }
+
+type filterOption struct {
+	Function OptionFunction
+}
+
+func (d filterOption) GetListOption() Option {
+	return d
+}
+
+func (d filterOption) GetWatchOption() Option {
+	return d
+}
+
+func (d filterOption) ApplyFunction() OptionFunction {
+	return d.Function
+}
+
+type fieldsOption struct {
+	Function OptionFunction
+}
+
+func (d fieldsOption) GetGetOption() Option {
+	return d
+}
+
+func (d fieldsOption) GetListOption() Option {
+	return d
+}
+
+func (d fieldsOption) ApplyFunction() OptionFunction {
+	return d.Function
+}
+
+type preconditionOption struct {
+	Function OptionFunction
+}
+
+func (d preconditionOption) GetUpdateOption() Option {
+	return d
+}
+
+func (d preconditionOption) GetDeleteOption() Option {
+	return d
+}
+
+func (d preconditionOption) ApplyFunction() OptionFunction {
+	return d.Function
+}
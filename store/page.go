@@ -0,0 +1,170 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/wazofski/storz/filter"
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/store/options"
+)
+
+// Page is a list result along with the token of the next page,
+// Continue is empty when the page is not full
+type Page struct {
+	Objects  ObjectList
+	Continue string
+}
+
+// ContinueToken marks the position of the last listed object
+// in the order of the list it was taken from.
+//
+// Every store orders lists the same way: by the order keys, missing values
+// first in ascending and last in descending order, then by primary key
+// in the direction of the last key so the order is stable across pages,
+// lists without order keys are ordered by primary key in either direction.
+// A list resumes after the token with the objects sharing the values
+// of the preceding keys whose next key comes after the token,
+// and with the ones sharing all the values whose primary key does.
+type ContinueToken struct {
+	Order      []options.OrderSetting `json:"o,omitempty"`
+	Descending bool                   `json:"d,omitempty"`
+	Values     []interface{}          `json:"v,omitempty"`
+	Key        string                 `json:"k"`
+}
+
+func (t ContinueToken) String() string {
+	data, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// KeyDescending is the direction of the primary key order,
+// it follows the last order key
+func (t ContinueToken) KeyDescending() bool {
+	if len(t.Order) > 0 {
+		return t.Order[len(t.Order)-1].Descending
+	}
+
+	return t.Descending
+}
+
+// ContinueFrom decodes the continue option and checks
+// it was issued for the same order, nil when there is none
+func ContinueFrom(copt options.CommonOptionHolder) (*ContinueToken, error) {
+	if len(copt.Continue) == 0 {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(copt.Continue)
+	if err != nil {
+		return nil, constants.ErrInvalidToken
+	}
+
+	res := &ContinueToken{}
+	err = json.Unmarshal(data, res)
+	if err != nil {
+		return nil, constants.ErrInvalidToken
+	}
+
+	if len(res.Order) != len(copt.Order) ||
+		len(res.Values) != len(copt.Order) ||
+		res.Descending != copt.Descending {
+		return nil, constants.ErrInvalidToken
+	}
+
+	for i, o := range copt.Order {
+		if res.Order[i] != o {
+			return nil, constants.ErrInvalidToken
+		}
+	}
+
+	return res, nil
+}
+
+// Pager is implemented by stores able to list a page along with its
+// continue token, the token of a trimmed list is taken before trimming
+// since the listed objects may miss the order keys and the primary key
+type Pager interface {
+	ListPage(context.Context, ObjectIdentity, ...options.ListOption) (Page, error)
+}
+
+// Full tells whether a list of the given length fills its page,
+// only full pages are followed by another one
+func Full(n int, copt options.CommonOptionHolder) bool {
+	return copt.PageSize > 0 && n == copt.PageSize
+}
+
+func NextToken(obj Object, copt options.CommonOptionHolder) string {
+	values := []interface{}{}
+	for _, o := range copt.Order {
+		values = append(values, OrderValue(obj, o.Key))
+	}
+
+	return TokenOf(copt, values, obj.PrimaryKey())
+}
+
+// TokenOf is the token following the object with the given order values
+// and primary key in the order of the options
+func TokenOf(copt options.CommonOptionHolder, values []interface{}, key string) string {
+	return ContinueToken{
+		Order:      copt.Order,
+		Descending: copt.Descending,
+		Values:     values,
+		Key:        key,
+	}.String()
+}
+
+// OrderValue is the JSON value at the given path of the object
+func OrderValue(obj Object, path string) interface{} {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+
+	doc := make(map[string]interface{})
+	if json.Unmarshal(data, &doc) != nil {
+		return nil
+	}
+
+	res, _ := filter.Lookup(doc, path)
+	return res
+}
+
+func ListPage(
+	ctx context.Context,
+	st Store,
+	identity ObjectIdentity,
+	opt ...options.ListOption) (Page, error) {
+
+	pager, ok := st.(Pager)
+	if ok {
+		return pager.ListPage(ctx, identity, opt...)
+	}
+
+	// options specific to other stores are of no interest here
+	copt := options.CommonOptionHolderFactory()
+	for _, o := range opt {
+		o.ApplyFunction()(&copt)
+	}
+
+	// trimmed objects cannot be continued from
+	if copt.Fields != nil && copt.PageSize > 0 {
+		return Page{}, constants.ErrUnsupported
+	}
+
+	ret, err := st.List(ctx, identity, opt...)
+	if err != nil {
+		return Page{}, err
+	}
+
+	res := Page{
+		Objects: ret,
+	}
+
+	if Full(len(ret), copt) {
+		res.Continue = NextToken(ret[len(ret)-1], copt)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,38 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/wazofski/storz/filter"
+)
+
+// Time is the type of time properties, its JSON form is
+// in UTC and of a fixed width so that stores can order by it
+type Time struct {
+	time.Time
+}
+
+func TimeOf(t time.Time) Time {
+	return Time{Time: t.UTC().Round(0)}
+}
+
+func (t Time) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.UTC().Format(filter.TimeFormat))
+}
+
+func (t *Time) UnmarshalJSON(data []byte) error {
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, str)
+	if err != nil {
+		return err
+	}
+
+	t.Time = parsed.UTC()
+	return nil
+}
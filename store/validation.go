@@ -0,0 +1,192 @@
+package store
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/wazofski/storz/internal/constants"
+)
+
+// Validator is implemented by generated objects and structures
+// checking the rules declared in the model
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError names the property breaking a model rule
+type ValidationError struct {
+	Path string
+	Rule string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Path) == 0 {
+		return fmt.Sprintf("%s: %s", constants.ErrInvalidObject, e.Rule)
+	}
+
+	return fmt.Sprintf("%s: %s %s", constants.ErrInvalidObject, e.Path, e.Rule)
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == constants.ErrInvalidObject
+}
+
+// Validate checks the object when it implements Validator
+func Validate(obj Object) error {
+	v, ok := obj.(Validator)
+	if !ok {
+		return nil
+	}
+
+	return v.Validate()
+}
+
+func invalid(path string, format string, a ...interface{}) error {
+	return &ValidationError{
+		Path: path,
+		Rule: fmt.Sprintf(format, a...),
+	}
+}
+
+// CheckRequired rejects unset optional values and empty strings, lists and maps,
+// other values are required to be present when unmarshalling only
+func CheckRequired(path string, val interface{}) error {
+	v := reflect.ValueOf(val)
+	if !v.IsValid() {
+		return invalid(path, "is required")
+	}
+
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		if v.Len() == 0 {
+			return invalid(path, "is required")
+		}
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return invalid(path, "is required")
+		}
+	}
+
+	return nil
+}
+
+func CheckMin(path string, val float64, min float64) error {
+	if val < min {
+		return invalid(path, "is less than %v", min)
+	}
+
+	return nil
+}
+
+func CheckMax(path string, val float64, max float64) error {
+	if val > max {
+		return invalid(path, "is greater than %v", max)
+	}
+
+	return nil
+}
+
+func CheckMinLength(path string, length int, min int) error {
+	if length < min {
+		return invalid(path, "is shorter than %d", min)
+	}
+
+	return nil
+}
+
+func CheckMaxLength(path string, length int, max int) error {
+	if length > max {
+		return invalid(path, "is longer than %d", max)
+	}
+
+	return nil
+}
+
+var patterns sync.Map
+
+func CheckPattern(path string, val string, pattern string) error {
+	re, ok := patterns.Load(pattern)
+	if !ok {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return invalid(path, "has an invalid pattern %s", pattern)
+		}
+		re, _ = patterns.LoadOrStore(pattern, compiled)
+	}
+
+	if !re.(*regexp.Regexp).MatchString(val) {
+		return invalid(path, "does not match %s", pattern)
+	}
+
+	return nil
+}
+
+func CheckEnum(path string, val interface{}, values ...string) error {
+	str := fmt.Sprint(val)
+	for _, v := range values {
+		if v == str {
+			return nil
+		}
+	}
+
+	return invalid(path, "is not one of %v", values)
+}
+
+// ValidateNested checks structures, lists and maps of structures
+// prefixing the paths of their errors with the property path
+func ValidateNested(path string, val interface{}) error {
+	if v, ok := val.(Validator); ok {
+		return NestedError(path, v.Validate())
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			err := ValidateNested(
+				fmt.Sprintf("%s.%d", path, i),
+				rv.Index(i).Interface())
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := []string{}
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, fmt.Sprint(k.Interface()))
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			err := ValidateNested(
+				fmt.Sprintf("%s.%s", path, k),
+				rv.MapIndex(reflect.ValueOf(k)).Interface())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// NestedError prefixes the path of validation errors
+// with the path of the property holding them
+func NestedError(path string, err error) error {
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		return err
+	}
+
+	if len(verr.Path) > 0 {
+		path = fmt.Sprintf("%s.%s", path, verr.Path)
+	}
+
+	return &ValidationError{
+		Path: path,
+		Rule: verr.Rule,
+	}
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"context"
+
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/store/options"
+)
+
+type EventType string
+
+const (
+	EventCreated EventType = "created"
+	EventUpdated EventType = "updated"
+	EventDeleted EventType = "deleted"
+)
+
+type Event struct {
+	Type   EventType `json:"type"`
+	Object Object    `json:"object"`
+}
+
+// Watcher is implemented by stores able to notify about object changes.
+// The returned channel is closed when the context is done
+// or when the consumer falls too far behind.
+type Watcher interface {
+	Watch(context.Context, ObjectIdentity, ...options.WatchOption) (<-chan Event, error)
+}
+
+func Watch(
+	ctx context.Context,
+	st Store,
+	identity ObjectIdentity,
+	opt ...options.WatchOption) (<-chan Event, error) {
+
+	watcher, ok := st.(Watcher)
+	if !ok {
+		return nil, constants.ErrUnsupported
+	}
+
+	return watcher.Watch(ctx, identity, opt...)
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/wazofski/storz/mongo"
 	"github.com/wazofski/storz/react"
 	"github.com/wazofski/storz/rest"
+	"github.com/wazofski/storz/route"
 	"github.com/wazofski/storz/sql"
 	"github.com/wazofski/storz/store"
 )
@@ -55,7 +56,10 @@ func suites(suite string) {
 				rest.ActionGet, rest.ActionCreate,
 				rest.ActionDelete, rest.ActionUpdate),
 			rest.TypeMethods(generated.SecondWorldKind(),
-				rest.ActionGet, rest.ActionCreate, rest.ActionDelete))
+				rest.ActionGet, rest.ActionCreate, rest.ActionDelete),
+			rest.TypeMethods(generated.CitizenKind(),
+				rest.ActionGet, rest.ActionCreate,
+				rest.ActionDelete, rest.ActionUpdate))
 
 		cancel = srv.Listen(8000)
 
@@ -99,6 +103,17 @@ func suites(suite string) {
 			cache.Factory(s1))
 	}
 
+	stores["route"] = func() {
+		clt = store.New(
+			sch,
+			route.Factory(
+				store.New(sch, memory.Factory()),
+				route.Mapping{
+					Kind:  generated.WorldKind(),
+					Store: store.New(sch, memory.Factory()),
+				}))
+	}
+
 	stores[suite]()
 }
 
@@ -1,17 +1,26 @@
 package common_test
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"sort"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/wazofski/storz/filter"
 	"github.com/wazofski/storz/generated"
+	"github.com/wazofski/storz/internal/constants"
 	"github.com/wazofski/storz/store"
 	"github.com/wazofski/storz/store/options"
 	"github.com/wazofski/storz/utils"
 )
 
+func email(address string) *string {
+	return &address
+}
+
 var _ = Describe("common", func() {
 
 	worldName := "c137zxczx"
@@ -554,4 +563,893 @@ var _ = Describe("common", func() {
 		Expect(world.Spec().Description()).To(Equal(worldDescription))
 	})
 
+	It("can CREATE objects to FILTER", func() {
+		ret, err := clt.List(ctx, generated.WorldKindIdentity())
+		Expect(err).To(BeNil())
+		for _, r := range ret {
+			err = clt.Delete(ctx, r.Metadata().Identity())
+			Expect(err).To(BeNil())
+		}
+
+		for i := 1; i <= 4; i++ {
+			world := generated.WorldFactory()
+			world.Spec().SetName(fmt.Sprintf("f%d", i))
+			world.Spec().Nested().SetCounter(i)
+			world.Spec().Nested().SetAlive(i%2 == 0)
+
+			_, err = clt.Create(ctx, world)
+			Expect(err).To(BeNil())
+		}
+	})
+
+	filterMatrix := []struct {
+		Expr  *filter.Expression
+		Names []string
+	}{
+		{filter.Eq("spec.name", "f1"), []string{"f1"}},
+		{filter.Ne("spec.name", "f1"), []string{"f2", "f3", "f4"}},
+		{filter.Lt("spec.nested.counter", 2), []string{"f1"}},
+		{filter.Le("spec.nested.counter", 2), []string{"f1", "f2"}},
+		{filter.Gt("spec.nested.counter", 2), []string{"f3", "f4"}},
+		{filter.Ge("spec.nested.counter", 2), []string{"f2", "f3", "f4"}},
+		{filter.In("spec.name", "f1", "f3", "f9"), []string{"f1", "f3"}},
+		{filter.Eq("spec.nested.alive", true), []string{"f2", "f4"}},
+		{filter.And(
+			filter.Gt("spec.nested.counter", 2),
+			filter.Eq("spec.nested.alive", true)), []string{"f4"}},
+		{filter.Or(
+			filter.Eq("spec.name", "f1"),
+			filter.Eq("spec.name", "f4")), []string{"f1", "f4"}},
+		{filter.Not(
+			filter.Eq("spec.nested.alive", true)), []string{"f1", "f3"}},
+		{filter.Not(
+			filter.Or(
+				filter.Lt("spec.nested.counter", 2),
+				filter.Gt("spec.nested.counter", 3))), []string{"f2", "f3"}},
+		{filter.Eq("spec.name", "f9"), []string{}},
+	}
+
+	for _, entry := range filterMatrix {
+		expr := entry.Expr
+		names := entry.Names
+
+		It(fmt.Sprintf("can LIST and FILTER %s", expr), func() {
+			ret, err := clt.List(
+				ctx,
+				generated.WorldKindIdentity(),
+				options.Filter(expr),
+				options.OrderBy("spec.name"))
+
+			Expect(err).To(BeNil())
+			Expect(ret).ToNot(BeNil())
+
+			res := []string{}
+			for _, r := range ret {
+				res = append(res, r.(generated.World).Spec().Name())
+			}
+			Expect(res).To(Equal(names))
+		})
+	}
+
+	It("can CREATE citizens to FILTER BY missing props", func() {
+		for i, score := range []int{8, 3, -1} {
+			citizen := generated.CitizenFactory()
+			citizen.Spec().SetName(fmt.Sprintf("m%d", i+1))
+			citizen.Spec().SetEmail(email(fmt.Sprintf("m%d@storz", i+1)))
+			if score >= 0 {
+				s := score
+				citizen.Spec().SetScore(&s)
+			}
+
+			_, err := clt.Create(ctx, citizen)
+			Expect(err).To(BeNil())
+		}
+	})
+
+	missingMatrix := []struct {
+		Expr  *filter.Expression
+		Names []string
+	}{
+		{filter.Eq("spec.score", 8), []string{"m1"}},
+		{filter.Ne("spec.score", 8), []string{"m2", "m3"}},
+		{filter.Not(filter.Eq("spec.score", 8)), []string{"m2", "m3"}},
+		{filter.Gt("spec.score", 5), []string{"m1"}},
+		{filter.Not(filter.Gt("spec.score", 5)), []string{"m2", "m3"}},
+		{filter.In("spec.score", 3, 8), []string{"m1", "m2"}},
+		{filter.Not(filter.In("spec.score", 3)), []string{"m1", "m3"}},
+		{filter.Eq("spec.score", nil), []string{"m3"}},
+		{filter.Ne("spec.score", nil), []string{"m1", "m2"}},
+		{filter.Not(filter.Eq("spec.score", nil)), []string{"m1", "m2"}},
+	}
+
+	for _, entry := range missingMatrix {
+		expr := entry.Expr
+		names := entry.Names
+
+		It(fmt.Sprintf("can LIST and FILTER BY missing props %s", expr), func() {
+			ret, err := clt.List(
+				ctx,
+				generated.CitizenKindIdentity(),
+				options.KeyFilter("m1", "m2", "m3"),
+				options.Filter(expr),
+				options.OrderBy("spec.name"))
+
+			Expect(err).To(BeNil())
+
+			res := []string{}
+			for _, r := range ret {
+				res = append(res, r.PrimaryKey())
+			}
+			Expect(res).To(Equal(names))
+		})
+	}
+
+	It("can DELETE citizens filtered BY missing props", func() {
+		for i := 1; i <= 3; i++ {
+			err := clt.Delete(ctx, generated.CitizenIdentity(fmt.Sprintf("m%d", i)))
+			Expect(err).To(BeNil())
+		}
+	})
+
+	It("can LIST FILTER and paginate", func() {
+		ret, err := clt.List(
+			ctx,
+			generated.WorldKindIdentity(),
+			options.Filter(filter.Ge("spec.nested.counter", 2)),
+			options.OrderBy("spec.name"),
+			options.OrderDescending(),
+			options.PageSize(2))
+
+		Expect(err).To(BeNil())
+		Expect(len(ret)).To(Equal(2))
+		Expect(ret[0].(generated.World).Spec().Name()).To(Equal("f4"))
+		Expect(ret[1].(generated.World).Spec().Name()).To(Equal("f3"))
+	})
+
+	It("cannot LIST and FILTER expressions BY nonexistent props", func() {
+		ret, err := clt.List(
+			ctx,
+			generated.WorldKindIdentity(),
+			options.Filter(
+				filter.And(
+					filter.Eq("spec.name", "f1"),
+					filter.Gt("spec.askdjhasd", 1))))
+
+		Expect(err).ToNot(BeNil())
+		Expect(ret).To(BeNil())
+	})
+
+	It("cannot LIST and FILTER BY values of another type", func() {
+		invalid := []struct {
+			Identity store.ObjectIdentity
+			Expr     *filter.Expression
+		}{
+			{generated.WorldKindIdentity(), filter.Gt("spec.name", 1)},
+			{generated.WorldKindIdentity(), filter.Eq("spec.nested.counter", false)},
+			{generated.WorldKindIdentity(), filter.In("spec.nested.alive", true, "yes")},
+			{generated.WorldKindIdentity(), filter.Eq("spec.nested", 1)},
+			{generated.CitizenKindIdentity(), filter.Eq("spec.nicknames", "anonymous")},
+			{generated.CitizenKindIdentity(), filter.In("spec.score", 3, nil)},
+			{generated.CitizenKindIdentity(), filter.Gt("spec.score", nil)},
+			{generated.CitizenKindIdentity(), filter.Le("spec.score", nil)},
+		}
+
+		for _, entry := range invalid {
+			ret, err := clt.List(ctx, entry.Identity, options.Filter(entry.Expr))
+			Expect(err).ToNot(BeNil(), entry.Expr.String())
+			Expect(ret).To(BeNil())
+		}
+	})
+
+	It("cannot LIST and ORDER BY nonexistent props", func() {
+		ret, err := clt.List(
+			ctx,
+			generated.WorldKindIdentity(),
+			options.OrderBy("spec.askdjhasd"))
+
+		Expect(err).ToNot(BeNil())
+		Expect(ret).To(BeNil())
+
+		ret, err = clt.List(
+			ctx,
+			generated.WorldKindIdentity(),
+			options.OrderBy("spec.name') DESC --"))
+
+		Expect(err).ToNot(BeNil())
+		Expect(ret).To(BeNil())
+	})
+
+	It("can LIST and filter by quoted primary keys", func() {
+		ret, err := clt.List(
+			ctx,
+			generated.WorldKindIdentity(),
+			options.KeyFilter("x') OR ('1'='1"))
+
+		Expect(err).To(BeNil())
+		Expect(len(ret)).To(Equal(0))
+	})
+
+	It("can track object revisions", func() {
+		world := generated.WorldFactory()
+		world.Spec().SetName("revisioned")
+
+		ret, err := clt.Create(ctx, world)
+		Expect(err).To(BeNil())
+		Expect(ret.Metadata().Revision()).To(Equal(int64(1)))
+
+		world = ret.(generated.World)
+		world.Spec().SetDescription("first")
+		ret, err = clt.Update(ctx, world.Metadata().Identity(), world)
+		Expect(err).To(BeNil())
+		Expect(ret.Metadata().Revision()).To(Equal(int64(2)))
+
+		ret, err = clt.Get(ctx, generated.WorldIdentity("revisioned"))
+		Expect(err).To(BeNil())
+		Expect(ret.Metadata().Revision()).To(Equal(int64(2)))
+	})
+
+	It("cannot UPDATE with a stale revision", func() {
+		world := generated.WorldFactory()
+		world.Spec().SetName("revisioned")
+		world.Spec().SetDescription("stale")
+
+		ret, err := clt.Update(ctx,
+			generated.WorldIdentity("revisioned"), world,
+			options.IfRevision(1))
+		Expect(err).ToNot(BeNil())
+		Expect(ret).To(BeNil())
+
+		ret, err = clt.Update(ctx,
+			generated.WorldIdentity("revisioned"), world,
+			options.IfRevision(2))
+		Expect(err).To(BeNil())
+		Expect(ret.Metadata().Revision()).To(Equal(int64(3)))
+		Expect(ret.(generated.World).Spec().Description()).To(Equal("stale"))
+	})
+
+	It("cannot DELETE with a stale revision", func() {
+		err := clt.Delete(ctx,
+			generated.WorldIdentity("revisioned"),
+			options.IfRevision(2))
+		Expect(err).ToNot(BeNil())
+
+		err = clt.Delete(ctx,
+			generated.WorldIdentity("revisioned"),
+			options.IfRevision(3))
+		Expect(err).To(BeNil())
+	})
+
+	It("can BATCH writes", func() {
+		first := generated.WorldFactory()
+		first.Spec().SetName("batched1")
+		second := generated.WorldFactory()
+		second.Spec().SetName("batched2")
+
+		ret, err := store.Batch(ctx, clt, []store.Op{
+			store.CreateOp(first),
+			store.CreateOp(second),
+		})
+		if err == constants.ErrUnsupported {
+			Skip("batches are not supported")
+		}
+		Expect(err).To(BeNil())
+		Expect(len(ret)).To(Equal(2))
+
+		first = ret[0].(generated.World)
+		first.Spec().SetDescription("batched")
+
+		ret, err = store.Batch(ctx, clt, []store.Op{
+			store.UpdateOp(first.Metadata().Identity(), first),
+			store.DeleteOp(generated.WorldIdentity("batched2")),
+		})
+		Expect(err).To(BeNil())
+		Expect(len(ret)).To(Equal(2))
+		Expect(ret[0].(generated.World).Spec().Description()).To(Equal("batched"))
+		Expect(ret[1]).To(BeNil())
+
+		obj, err := clt.Get(ctx, generated.WorldIdentity("batched1"))
+		Expect(err).To(BeNil())
+		Expect(obj.(generated.World).Spec().Description()).To(Equal("batched"))
+
+		_, err = clt.Get(ctx, generated.WorldIdentity("batched2"))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("cannot BATCH writes partially", func() {
+		third := generated.WorldFactory()
+		third.Spec().SetName("batched3")
+		existing := generated.WorldFactory()
+		existing.Spec().SetName("batched1")
+
+		_, err := store.Batch(ctx, clt, []store.Op{
+			store.CreateOp(third),
+			store.CreateOp(existing),
+		})
+		if err == constants.ErrUnsupported {
+			Skip("batches are not supported")
+		}
+		Expect(err).ToNot(BeNil())
+
+		_, err = clt.Get(ctx, generated.WorldIdentity("batched3"))
+		Expect(err).ToNot(BeNil())
+
+		stale := store.DeleteOp(generated.WorldIdentity("batched1"))
+		stale.IfRevision = 1
+
+		_, err = store.Batch(ctx, clt, []store.Op{
+			store.DeleteOp(generated.WorldIdentity("batched1")),
+			stale,
+		})
+		Expect(err).ToNot(BeNil())
+
+		ret, err := clt.Get(ctx, generated.WorldIdentity("batched1"))
+		Expect(err).To(BeNil())
+		Expect(ret.Metadata().Revision()).To(Equal(int64(2)))
+
+		err = clt.Delete(ctx, generated.WorldIdentity("batched1"))
+		Expect(err).To(BeNil())
+	})
+
+	pagedNames := []string{"p0", "p1", "p2", "p3", "p4", "p5", "p6"}
+	pagedCounters := []int{3, 1, 2, 1, 2, 0, 2}
+
+	listPages := func(opts ...options.ListOption) []string {
+		res := []string{}
+		token := ""
+		for {
+			popts := append([]options.ListOption{
+				options.KeyFilter(pagedNames...),
+				options.PageSize(2),
+			}, opts...)
+			if len(token) > 0 {
+				popts = append(popts, options.Continue(token))
+			}
+
+			page, err := store.ListPage(ctx, clt, generated.WorldKindIdentity(), popts...)
+			Expect(err).To(BeNil())
+			Expect(len(page.Objects)).To(BeNumerically("<=", 2))
+
+			for _, o := range page.Objects {
+				res = append(res, o.PrimaryKey())
+			}
+
+			token = page.Continue
+			if len(token) == 0 {
+				return res
+			}
+		}
+	}
+
+	It("can LIST with continue tokens", func() {
+		for i, name := range pagedNames {
+			world := generated.WorldFactory()
+			world.Spec().SetName(name)
+			world.Spec().Nested().SetCounter(pagedCounters[i])
+			world.Spec().Nested().SetAlive(i%2 == 0)
+
+			_, err := clt.Create(ctx, world)
+			Expect(err).To(BeNil())
+		}
+
+		Expect(listPages()).To(Equal(pagedNames))
+
+		Expect(listPages(
+			options.OrderDescending())).To(Equal(
+			[]string{"p6", "p5", "p4", "p3", "p2", "p1", "p0"}))
+
+		Expect(listPages(
+			options.OrderBy("spec.nested.counter"))).To(Equal(
+			[]string{"p5", "p1", "p3", "p2", "p4", "p6", "p0"}))
+
+		Expect(listPages(
+			options.OrderBy("spec.nested.counter"),
+			options.OrderDescending())).To(Equal(
+			[]string{"p0", "p6", "p4", "p2", "p3", "p1", "p5"}))
+
+		Expect(listPages(
+			options.OrderBy("spec.nested.counter"),
+			options.OrderDescending(),
+			options.OrderBy("spec.name"))).To(Equal(
+			[]string{"p0", "p2", "p4", "p6", "p1", "p3", "p5"}))
+
+		Expect(listPages(
+			options.OrderBy("spec.nested.alive"),
+			options.OrderDescending(),
+			options.OrderBy("spec.nested.counter"))).To(Equal(
+			[]string{"p2", "p4", "p6", "p0", "p5", "p1", "p3"}))
+	})
+
+	It("can continue LIST of trimmed objects", func() {
+		listCounters := func(opts ...options.ListOption) []int {
+			res := []int{}
+			token := ""
+			for {
+				popts := append([]options.ListOption{
+					options.KeyFilter(pagedNames...),
+					options.Fields("spec.nested.counter"),
+					options.PageSize(2),
+				}, opts...)
+				if len(token) > 0 {
+					popts = append(popts, options.Continue(token))
+				}
+
+				page, err := store.ListPage(ctx, clt, generated.WorldKindIdentity(), popts...)
+				if err == constants.ErrUnsupported {
+					Skip("trimmed pages are not supported")
+				}
+				Expect(err).To(BeNil())
+
+				for _, o := range page.Objects {
+					world := o.(generated.World)
+					Expect(world.Spec().Name()).To(Equal(""))
+					res = append(res, world.Spec().Nested().Counter())
+				}
+				Expect(len(res)).To(BeNumerically("<=", len(pagedNames)))
+
+				token = page.Continue
+				if len(token) == 0 {
+					return res
+				}
+			}
+		}
+
+		Expect(listCounters()).To(Equal(pagedCounters))
+
+		Expect(listCounters(
+			options.OrderBy("spec.nested.counter"),
+			options.OrderDescending())).To(Equal(
+			[]int{3, 2, 2, 2, 1, 1, 0}))
+	})
+
+	It("cannot ORDER BY the same key twice", func() {
+		_, err := clt.List(ctx,
+			generated.WorldKindIdentity(),
+			options.OrderBy("spec.name"),
+			options.OrderBy("spec.name"))
+		Expect(err).ToNot(BeNil())
+
+		_, err = clt.List(ctx,
+			generated.WorldKindIdentity(),
+			options.OrderDescending(),
+			options.OrderDescending())
+		Expect(err).ToNot(BeNil())
+
+		_, err = clt.List(ctx,
+			generated.WorldKindIdentity(),
+			options.OrderDescending(),
+			options.OrderBy("spec.name"))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("can continue LIST after concurrent writes", func() {
+		page, err := store.ListPage(ctx, clt,
+			generated.WorldKindIdentity(),
+			options.KeyFilter(pagedNames...),
+			options.OrderBy("spec.name"),
+			options.PageSize(3))
+		Expect(err).To(BeNil())
+		Expect(len(page.Continue)).ToNot(Equal(0))
+
+		err = clt.Delete(ctx, generated.WorldIdentity("p0"))
+		Expect(err).To(BeNil())
+		err = clt.Delete(ctx, generated.WorldIdentity("p2"))
+		Expect(err).To(BeNil())
+
+		ret, err := clt.List(ctx,
+			generated.WorldKindIdentity(),
+			options.KeyFilter(pagedNames...),
+			options.OrderBy("spec.name"),
+			options.PageSize(3),
+			options.Continue(page.Continue))
+		Expect(err).To(BeNil())
+
+		res := []string{}
+		for _, r := range ret {
+			res = append(res, r.PrimaryKey())
+		}
+		Expect(res).To(Equal([]string{"p3", "p4", "p5"}))
+	})
+
+	It("cannot LIST with an invalid continue token", func() {
+		page, err := store.ListPage(ctx, clt,
+			generated.WorldKindIdentity(),
+			options.KeyFilter(pagedNames...),
+			options.OrderBy("spec.name"),
+			options.PageSize(2))
+		Expect(err).To(BeNil())
+		Expect(len(page.Continue)).ToNot(Equal(0))
+
+		_, err = clt.List(ctx,
+			generated.WorldKindIdentity(),
+			options.OrderBy("spec.nested.counter"),
+			options.Continue(page.Continue))
+		Expect(err).ToNot(BeNil())
+
+		_, err = clt.List(ctx,
+			generated.WorldKindIdentity(),
+			options.Continue("garbage"))
+		Expect(err).ToNot(BeNil())
+
+		for _, name := range pagedNames {
+			clt.Delete(ctx, generated.WorldIdentity(name))
+		}
+	})
+
+	It("can COUNT objects", func() {
+		for i := 1; i <= 3; i++ {
+			world := generated.WorldFactory()
+			world.Spec().SetName(fmt.Sprintf("count%d", i))
+			world.Spec().Nested().SetCounter(i)
+
+			_, err := clt.Create(ctx, world)
+			Expect(err).To(BeNil())
+		}
+
+		names := options.KeyFilter("count1", "count2", "count3")
+
+		total, err := store.Count(ctx, clt, generated.WorldKindIdentity(), names)
+		if err == constants.ErrUnsupported {
+			Skip("counting is not supported")
+		}
+		Expect(err).To(BeNil())
+		Expect(total).To(Equal(3))
+
+		total, err = store.Count(ctx, clt,
+			generated.WorldKindIdentity(),
+			names,
+			options.Filter(filter.Ge("spec.nested.counter", 2)),
+			options.PageSize(1))
+		Expect(err).To(BeNil())
+		Expect(total).To(Equal(2))
+
+		_, err = store.Count(ctx, clt,
+			generated.WorldKindIdentity(),
+			options.Filter(filter.Eq("spec.askdjhasd", 1)))
+		Expect(err).ToNot(BeNil())
+
+		for i := 1; i <= 3; i++ {
+			err = clt.Delete(ctx, generated.WorldIdentity(fmt.Sprintf("count%d", i)))
+			Expect(err).To(BeNil())
+		}
+
+		total, err = store.Count(ctx, clt, generated.WorldKindIdentity(), names)
+		Expect(err).To(BeNil())
+		Expect(total).To(Equal(0))
+	})
+
+	It("can GET and LIST with field projection", func() {
+		for i := 1; i <= 2; i++ {
+			world := generated.WorldFactory()
+			world.Spec().SetName(fmt.Sprintf("fields%d", i))
+			world.Spec().SetDescription("a long description")
+			world.Spec().Nested().SetCounter(i)
+			world.Status().SetDescription("a large status")
+
+			_, err := clt.Create(ctx, world)
+			Expect(err).To(BeNil())
+		}
+
+		ret, err := clt.Get(ctx,
+			generated.WorldIdentity("fields1"),
+			options.Fields("spec.name", "spec.nested.counter"))
+		Expect(err).To(BeNil())
+
+		world := ret.(generated.World)
+		Expect(world.Spec().Name()).To(Equal("fields1"))
+		Expect(world.Spec().Nested().Counter()).To(Equal(1))
+		Expect(world.Spec().Description()).To(Equal(""))
+		Expect(world.Status().Description()).To(Equal(""))
+
+		list, err := clt.List(ctx,
+			generated.WorldKindIdentity(),
+			options.KeyFilter("fields1", "fields2"),
+			options.OrderBy("spec.name"),
+			options.Fields("spec.name", "metadata.identity"))
+		Expect(err).To(BeNil())
+		Expect(len(list)).To(Equal(2))
+
+		for i, o := range list {
+			world := o.(generated.World)
+			Expect(world.Spec().Name()).To(Equal(fmt.Sprintf("fields%d", i+1)))
+			Expect(len(world.Metadata().Identity())).ToNot(BeZero())
+			Expect(world.Spec().Description()).To(Equal(""))
+			Expect(world.Status().Description()).To(Equal(""))
+		}
+
+		for i := 1; i <= 2; i++ {
+			err = clt.Delete(ctx, generated.WorldIdentity(fmt.Sprintf("fields%d", i)))
+			Expect(err).To(BeNil())
+		}
+	})
+
+	It("cannot GET or LIST with nonexistent fields", func() {
+		world := generated.WorldFactory()
+		world.Spec().SetName("fields")
+
+		_, err := clt.Create(ctx, world)
+		Expect(err).To(BeNil())
+
+		_, err = clt.Get(ctx,
+			generated.WorldIdentity("fields"),
+			options.Fields("spec.askdjhasd"))
+		Expect(err).ToNot(BeNil())
+
+		_, err = clt.List(ctx,
+			generated.WorldKindIdentity(),
+			options.Fields("spec.askdjhasd"))
+		Expect(err).ToNot(BeNil())
+
+		err = clt.Delete(ctx, generated.WorldIdentity("fields"))
+		Expect(err).To(BeNil())
+	})
+
+	It("cannot CREATE or UPDATE objects with taken unique values", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("c1")
+		citizen.Spec().SetEmail(email("c@storz"))
+
+		_, err := clt.Create(ctx, citizen)
+		Expect(err).To(BeNil())
+
+		another := generated.CitizenFactory()
+		another.Spec().SetName("c2")
+		another.Spec().SetEmail(email("c@storz"))
+
+		_, err = clt.Create(ctx, another)
+		Expect(err).To(Equal(constants.ErrUniqueViolation))
+
+		another.Spec().SetEmail(email("c2@storz"))
+		_, err = clt.Create(ctx, another)
+		Expect(err).To(BeNil())
+
+		another.Spec().SetEmail(email("c@storz"))
+		_, err = clt.Update(ctx, generated.CitizenIdentity("c2"), another)
+		Expect(err).To(Equal(constants.ErrUniqueViolation))
+
+		ret, err := clt.Get(ctx, generated.CitizenIdentity("c2"))
+		Expect(err).To(BeNil())
+		Expect(*ret.(generated.Citizen).Spec().Email()).To(Equal("c2@storz"))
+
+		citizen.Spec().SetEmail(email("c1@storz"))
+		_, err = clt.Update(ctx, generated.CitizenIdentity("c1"), citizen)
+		Expect(err).To(BeNil())
+
+		_, err = clt.Update(ctx, generated.CitizenIdentity("c2"), another)
+		Expect(err).To(BeNil())
+
+		// unset optional values never collide
+		for _, name := range []string{"c3", "c4"} {
+			unset := generated.CitizenFactory()
+			unset.Spec().SetName(name)
+			_, err = clt.Create(ctx, unset)
+			Expect(err).To(BeNil())
+		}
+
+		citizen.Spec().SetEmail(nil)
+		_, err = clt.Update(ctx, generated.CitizenIdentity("c1"), citizen)
+		Expect(err).To(BeNil())
+
+		for _, name := range []string{"c1", "c2", "c3", "c4"} {
+			err = clt.Delete(ctx, generated.CitizenIdentity(name))
+			Expect(err).To(BeNil())
+		}
+	})
+
+	It("cannot CREATE or UPDATE objects with unknown enum values", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("bogus")
+		citizen.Spec().SetRole("bogus")
+
+		_, err := clt.Create(ctx, citizen)
+		Expect(errors.Is(err, constants.ErrInvalidObject)).To(BeTrue())
+
+		citizen.Spec().SetRole(generated.RoleMayor)
+		_, err = clt.Create(ctx, citizen)
+		Expect(err).To(BeNil())
+
+		citizen.Spec().SetRole("bogus")
+		_, err = clt.Update(ctx, generated.CitizenIdentity("bogus"), citizen)
+		Expect(errors.Is(err, constants.ErrInvalidObject)).To(BeTrue())
+
+		ret, err := clt.Get(ctx, generated.CitizenIdentity("bogus"))
+		Expect(err).To(BeNil())
+		Expect(ret.(generated.Citizen).Spec().Role()).To(Equal(generated.RoleMayor))
+
+		err = clt.Delete(ctx, generated.CitizenIdentity("bogus"))
+		Expect(err).To(BeNil())
+	})
+
+	It("can GET and LIST projected objects with default values", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("defaults")
+		citizen.Spec().SetEmail(email("defaults@storz"))
+		citizen.Spec().SetRole("mayor")
+
+		_, err := clt.Create(ctx, citizen)
+		Expect(err).To(BeNil())
+
+		ret, err := clt.Get(ctx, generated.CitizenIdentity("defaults"))
+		Expect(err).To(BeNil())
+		spec := ret.(generated.Citizen).Spec()
+		Expect(spec.Address().Street()).To(Equal("main"))
+		Expect(spec.Nicknames()).To(Equal([]string{"anonymous"}))
+
+		list, err := clt.List(ctx, generated.CitizenKindIdentity(),
+			options.KeyFilter("defaults"),
+			options.Fields("spec.email"))
+		Expect(err).To(BeNil())
+		Expect(len(list)).To(Equal(1))
+
+		spec = list[0].(generated.Citizen).Spec()
+		Expect(*spec.Email()).To(Equal("defaults@storz"))
+		Expect(spec.Name()).To(Equal(""))
+		Expect(spec.Role()).To(Equal(generated.RoleCitizen))
+
+		err = clt.Delete(ctx, generated.CitizenIdentity("defaults"))
+		Expect(err).To(BeNil())
+	})
+
+	It("can filter and ORDER BY time, duration and optional props", func() {
+		at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		names := []string{"t1", "t2", "t3"}
+		offsets := []time.Duration{
+			time.Second, 500 * time.Millisecond, 2 * time.Second}
+
+		for i, name := range names {
+			citizen := generated.CitizenFactory()
+			citizen.Spec().SetName(name)
+			citizen.Spec().SetEmail(email(name + "@storz"))
+			citizen.Spec().SetBorn(store.TimeOf(at.Add(offsets[i])))
+			citizen.Spec().SetLease(time.Duration(i+1) * time.Hour)
+			if i > 0 {
+				score := 10 - i
+				citizen.Spec().SetScore(&score)
+			}
+
+			_, err := clt.Create(ctx, citizen)
+			Expect(err).To(BeNil())
+		}
+
+		keys := func(list store.ObjectList) []string {
+			res := []string{}
+			for _, o := range list {
+				res = append(res, o.PrimaryKey())
+			}
+			return res
+		}
+
+		ret, err := clt.List(ctx, generated.CitizenKindIdentity(),
+			options.KeyFilter(names...),
+			options.OrderBy("spec.born"))
+		Expect(err).To(BeNil())
+		Expect(keys(ret)).To(Equal([]string{"t2", "t1", "t3"}))
+
+		ret, err = clt.List(ctx, generated.CitizenKindIdentity(),
+			options.KeyFilter(names...),
+			options.Filter(filter.Gt("spec.born", at.Add(time.Second))))
+		Expect(err).To(BeNil())
+		Expect(keys(ret)).To(Equal([]string{"t3"}))
+
+		ret, err = clt.List(ctx, generated.CitizenKindIdentity(),
+			options.KeyFilter(names...),
+			options.Filter(filter.Le("spec.lease", 2*time.Hour)),
+			options.OrderBy("spec.lease"),
+			options.OrderDescending())
+		Expect(err).To(BeNil())
+		Expect(keys(ret)).To(Equal([]string{"t2", "t1"}))
+
+		ret, err = clt.List(ctx, generated.CitizenKindIdentity(),
+			options.KeyFilter(names...),
+			options.Filter(filter.Eq("spec.score", 8)))
+		Expect(err).To(BeNil())
+		Expect(keys(ret)).To(Equal([]string{"t3"}))
+		Expect(*ret[0].(generated.Citizen).Spec().Score()).To(Equal(8))
+
+		ret, err = clt.List(ctx, generated.CitizenKindIdentity(),
+			options.KeyFilter(names...),
+			options.OrderBy("spec.score"))
+		Expect(err).To(BeNil())
+		Expect(keys(ret)).To(Equal([]string{"t1", "t3", "t2"}))
+		Expect(ret[0].(generated.Citizen).Spec().Score()).To(BeNil())
+
+		for _, name := range names {
+			err = clt.Delete(ctx, generated.CitizenIdentity(name))
+			Expect(err).To(BeNil())
+		}
+	})
+
+	It("can keep labels and annotations", func() {
+		world := generated.WorldFactory()
+		world.Spec().SetName("labeled")
+		world.Metadata().(store.MetaSetter).SetLabels(
+			map[string]string{"app": "storz"})
+		world.Metadata().(store.MetaSetter).SetAnnotations(
+			map[string]string{"note": "first"})
+
+		_, err := clt.Create(ctx, world)
+		Expect(err).To(BeNil())
+
+		ret, err := clt.Get(ctx, generated.WorldIdentity("labeled"))
+		Expect(err).To(BeNil())
+		Expect(ret.Metadata().Labels()).To(Equal(map[string]string{"app": "storz"}))
+		Expect(ret.Metadata().Annotations()).To(Equal(map[string]string{"note": "first"}))
+
+		ret.Metadata().(store.MetaSetter).SetAnnotations(
+			map[string]string{"note": "second"})
+		_, err = clt.Update(ctx, ret.Metadata().Identity(), ret)
+		Expect(err).To(BeNil())
+
+		ret, err = clt.Get(ctx, generated.WorldIdentity("labeled"))
+		Expect(err).To(BeNil())
+		Expect(ret.Metadata().Labels()).To(Equal(map[string]string{"app": "storz"}))
+		Expect(ret.Metadata().Annotations()).To(Equal(map[string]string{"note": "second"}))
+
+		err = clt.Delete(ctx, generated.WorldIdentity("labeled"))
+		Expect(err).To(BeNil())
+	})
+
+	It("can LIST and COUNT by labels", func() {
+		labels := []map[string]string{
+			{"app": "storz", "tier": "web", "app.kubernetes.io/name": "storz"},
+			{"app": "storz", "tier": "db"},
+			{"app": "other"},
+			nil,
+		}
+
+		for i, l := range labels {
+			world := generated.WorldFactory()
+			world.Spec().SetName(fmt.Sprintf("label%d", i))
+			world.Metadata().(store.MetaSetter).SetLabels(l)
+
+			_, err := clt.Create(ctx, world)
+			Expect(err).To(BeNil())
+		}
+
+		keys := func(list store.ObjectList) []string {
+			res := []string{}
+			for _, o := range list {
+				res = append(res, o.PrimaryKey())
+			}
+			sort.Strings(res)
+			return res
+		}
+
+		ret, err := clt.List(ctx, generated.WorldKindIdentity(),
+			options.LabelSelector(map[string]string{"app": "storz"}))
+		Expect(err).To(BeNil())
+		Expect(keys(ret)).To(Equal([]string{"label0", "label1"}))
+
+		ret, err = clt.List(ctx, generated.WorldKindIdentity(),
+			options.LabelSelector(map[string]string{"app": "storz", "tier": "db"}))
+		Expect(err).To(BeNil())
+		Expect(keys(ret)).To(Equal([]string{"label1"}))
+
+		ret, err = clt.List(ctx, generated.WorldKindIdentity(),
+			options.LabelSelector(map[string]string{"app.kubernetes.io/name": "storz"}))
+		Expect(err).To(BeNil())
+		Expect(keys(ret)).To(Equal([]string{"label0"}))
+
+		ret, err = clt.List(ctx, generated.WorldKindIdentity(),
+			options.LabelSelector(map[string]string{"tier": "cache"}))
+		Expect(err).To(BeNil())
+		Expect(len(ret)).To(Equal(0))
+
+		for _, key := range []string{`app"`, `app\`} {
+			_, err = clt.List(ctx, generated.WorldKindIdentity(),
+				options.LabelSelector(map[string]string{key: "storz"}))
+			Expect(err).ToNot(BeNil())
+		}
+
+		total, err := store.Count(ctx, clt, generated.WorldKindIdentity(),
+			options.LabelSelector(map[string]string{"app": "storz"}))
+		if err != constants.ErrUnsupported {
+			Expect(err).To(BeNil())
+			Expect(total).To(Equal(2))
+		}
+
+		for i := range labels {
+			err = clt.Delete(ctx, generated.WorldIdentity(fmt.Sprintf("label%d", i)))
+			Expect(err).To(BeNil())
+		}
+	})
 })
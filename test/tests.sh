@@ -5,6 +5,7 @@ echo "########### STARTING COMMON SUITE ###########"
 go test -ginkgo.v -args store=memory
 go test -ginkgo.v -args store=cache
 go test -ginkgo.v -args store=react
+go test -ginkgo.v -args store=route
 go test -ginkgo.v -args store=sqlite
 go test -ginkgo.v -args store=mysql
 go test -ginkgo.v -args store=mongo
@@ -4,11 +4,16 @@ go clean -cache
 echo "########### STARTING TEST SUITE #############"
 ginkgo -r -focus "storz"
 ginkgo -r -focus "mgen"
+ginkgo -r -focus "filter"
 
 ginkgo -r -focus "cache"
 ginkgo -r -focus "react"
+ginkgo -r -focus "validate"
+ginkgo -r -focus "route"
 ginkgo -r -focus "client"
 
+go test -race ./memory ./cache
+
 cd test
 ./tests.sh
 cd ..
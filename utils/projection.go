@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/wazofski/storz/filter"
+	"github.com/wazofski/storz/store"
+)
+
+func SetDocumentPath(doc map[string]interface{}, path string, val interface{}) {
+	tokens := strings.Split(path, ".")
+	current := doc
+	for _, t := range tokens[:len(tokens)-1] {
+		next, ok := current[t].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[t] = next
+		}
+		current = next
+	}
+
+	current[tokens[len(tokens)-1]] = val
+}
+
+// UnmarshalProjection makes an object of the kind out of the given paths of a document,
+// properties missing from the document keep their default values
+func UnmarshalProjection(
+	doc map[string]interface{},
+	schema store.SchemaHolder,
+	kind string,
+	fields []string) (store.Object, error) {
+
+	res := schema.ObjectForKind(kind)
+	if res == nil {
+		return nil, fmt.Errorf("unknown kind %s", kind)
+	}
+
+	res.Metadata().(store.MetaSetter).SetIdentity("")
+
+	// the paths are laid over the whole default document
+	// so required properties are never missing
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+
+	base := make(map[string]interface{})
+	err = json.Unmarshal(data, &base)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range fields {
+		val, ok := filter.Lookup(doc, f)
+		if ok {
+			SetDocumentPath(base, f, val)
+		}
+	}
+
+	data, err = json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func ProjectObject(
+	obj store.Object,
+	schema store.SchemaHolder,
+	fields []string) (store.Object, error) {
+
+	if len(fields) == 0 {
+		return obj, nil
+	}
+
+	data, err := Serialize(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(map[string]interface{})
+	err = json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return UnmarshalProjection(
+		doc,
+		schema,
+		obj.Metadata().Kind(),
+		fields)
+}
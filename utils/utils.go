@@ -2,6 +2,7 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -23,16 +24,29 @@ type _MetaHolder struct {
 }
 
 func CloneObject(obj store.Object, schema store.SchemaHolder) store.Object {
+	ret, err := CopyObject(obj, schema)
+	if err != nil {
+		log.Panic(err)
+	}
+	return ret
+}
+
+// CopyObject clones an object handed over to a store,
+// objects that do not unmarshal back are invalid
+func CopyObject(obj store.Object, schema store.SchemaHolder) (store.Object, error) {
 	ret := schema.ObjectForKind(obj.Metadata().Kind())
 	jsn, err := json.Marshal(obj)
 	if err != nil {
-		log.Panic(err)
+		return nil, err
 	}
 	err = json.Unmarshal(jsn, &ret)
+	if err != nil && !errors.Is(err, constants.ErrInvalidObject) {
+		err = fmt.Errorf("%w: %s", constants.ErrInvalidObject, err)
+	}
 	if err != nil {
-		log.Panic(err)
+		return nil, err
 	}
-	return ret
+	return ret, nil
 }
 
 func ReadStream(r io.ReadCloser) ([]byte, error) {
@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/wazofski/storz/filter"
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/store"
+	"github.com/wazofski/storz/store/options"
+	"golang.org/x/exp/slices"
+)
+
+// InvalidPath wraps ErrInvalidFilter with the offending property path
+func InvalidPath(path string) error {
+	return fmt.Errorf("%w: %s", constants.ErrInvalidFilter, path)
+}
+
+// ValidateFields makes sure every path exists in objects of the kind
+func ValidateFields(schema store.SchemaHolder, kind string, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	proto := schema.ObjectForKind(kind)
+	if proto == nil {
+		return constants.ErrNoSuchObject
+	}
+
+	optionals := store.Optionals(schema, kind)
+	for _, f := range fields {
+		if ObjectPath(proto, f) == nil && !slices.Contains(optionals, f) {
+			return InvalidPath(f)
+		}
+	}
+
+	return nil
+}
+
+// ValidateOptions checks the property paths used for filtering,
+// ordering and projection before they reach a query
+func ValidateOptions(
+	schema store.SchemaHolder,
+	kind string,
+	copt options.CommonOptionHolder) error {
+
+	paths := []string{}
+	if copt.PropFilter != nil {
+		paths = append(paths, copt.PropFilter.Key)
+	}
+
+	if copt.Filter != nil {
+		paths = append(paths, copt.Filter.Keys()...)
+	}
+
+	for _, o := range copt.Order {
+		paths = append(paths, o.Key)
+	}
+
+	if copt.Fields != nil {
+		paths = append(paths, copt.Fields...)
+	}
+
+	err := ValidateFields(schema, kind, paths)
+	if err != nil {
+		return err
+	}
+
+	if copt.Filter != nil {
+		return validateFilter(schema.ObjectForKind(kind), copt.Filter)
+	}
+
+	return nil
+}
+
+// filter values are required to be of the type of the compared property,
+// stores would otherwise coerce or match list elements differently
+func validateFilter(proto store.Object, expr *filter.Expression) error {
+	if expr.IsLogical() {
+		for _, x := range expr.Exprs {
+			err := validateFilter(proto, x)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	typ := PathType(proto, expr.Key)
+	if typ == "array" || typ == "object" {
+		return fmt.Errorf("%w: %s is not a scalar", constants.ErrInvalidFilter, expr.Key)
+	}
+
+	values := []interface{}{expr.Value}
+	if expr.Op == filter.OpIn {
+		values = expr.Value.([]interface{})
+	}
+
+	for _, v := range values {
+		// missing values are only matched by equality
+		if v == nil && (expr.Op == filter.OpEq || expr.Op == filter.OpNe) {
+			continue
+		}
+
+		if valueType(v) != typ {
+			return fmt.Errorf("%w: %s is compared to a value of another type",
+				constants.ErrInvalidFilter, expr.Key)
+		}
+	}
+
+	return nil
+}
+
+func valueType(val interface{}) string {
+	switch val.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+
+	return ""
+}
+
+// PathType is the JSON type of the property at the path of the object,
+// empty when the path does not lead to a property
+func PathType(obj interface{}, path string) string {
+	v := reflect.ValueOf(obj)
+	for _, t := range strings.Split(path, ".") {
+		v = resolve(v)
+		switch v.Kind() {
+		case reflect.Struct:
+			v = jsonField(v, t)
+		case reflect.Map:
+			v = reflect.Zero(v.Type().Elem())
+		default:
+			return ""
+		}
+		if !v.IsValid() {
+			return ""
+		}
+	}
+
+	v = resolve(v)
+	if !v.IsValid() {
+		return ""
+	}
+
+	t := v.Type()
+	if t.Kind() == reflect.Struct && t.Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) {
+		data, err := json.Marshal(reflect.Zero(t).Interface())
+		if err != nil || len(data) == 0 {
+			return ""
+		}
+		if data[0] == '"' {
+			return "string"
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		return "array"
+	case reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+
+	return ""
+}
+
+// resolve follows pointers and interfaces, unset pointers
+// lead to the zero value of their type
+func resolve(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if !v.IsNil() {
+			v = v.Elem()
+		} else if v.Kind() == reflect.Ptr {
+			v = reflect.Zero(v.Type().Elem())
+		} else {
+			return reflect.Value{}
+		}
+	}
+
+	return v
+}
+
+// jsonField is the field of the struct marshalled under the name,
+// fields of embedded structures included
+func jsonField(v reflect.Value, name string) reflect.Value {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if f.Anonymous && len(tag) == 0 {
+			embedded := resolve(v.Field(i))
+			if embedded.Kind() != reflect.Struct {
+				continue
+			}
+			res := jsonField(embedded, name)
+			if res.IsValid() {
+				return res
+			}
+			continue
+		}
+		if tag == name {
+			return v.Field(i)
+		}
+	}
+
+	return reflect.Value{}
+}
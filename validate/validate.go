@@ -0,0 +1,145 @@
+package validate
+
+import (
+	"context"
+
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/internal/logger"
+	"github.com/wazofski/storz/store"
+	"github.com/wazofski/storz/store/options"
+)
+
+type validateStore struct {
+	Schema store.SchemaHolder
+	Store  store.Store
+	Log    logger.Logger
+}
+
+func Factory(data store.Store) store.Factory {
+	return func(schema store.SchemaHolder) (store.Store, error) {
+		client := &validateStore{
+			Schema: schema,
+			Store:  data,
+			Log:    logger.Factory("validate"),
+		}
+
+		return client, nil
+	}
+}
+
+func (d *validateStore) Create(
+	ctx context.Context,
+	obj store.Object,
+	opt ...options.CreateOption) (store.Object, error) {
+
+	if obj == nil {
+		return nil, constants.ErrObjectNil
+	}
+
+	d.Log.Printf("create %s", obj.PrimaryKey())
+	err := store.Validate(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Store.Create(ctx, obj, opt...)
+}
+
+func (d *validateStore) Update(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	obj store.Object,
+	opt ...options.UpdateOption) (store.Object, error) {
+
+	if obj == nil {
+		return nil, constants.ErrObjectNil
+	}
+
+	d.Log.Printf("update %s", identity.Path())
+	err := store.Validate(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Store.Update(ctx, identity, obj, opt...)
+}
+
+func (d *validateStore) Delete(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.DeleteOption) error {
+
+	d.Log.Printf("delete %s", identity.Path())
+	return d.Store.Delete(ctx, identity, opt...)
+}
+
+func (d *validateStore) Get(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.GetOption) (store.Object, error) {
+
+	d.Log.Printf("get %s", identity.Path())
+	return d.Store.Get(ctx, identity, opt...)
+}
+
+func (d *validateStore) List(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (store.ObjectList, error) {
+
+	d.Log.Printf("list %s", identity.Type())
+	return d.Store.List(ctx, identity, opt...)
+}
+
+func (d *validateStore) ListPage(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (store.Page, error) {
+
+	d.Log.Printf("list %s", identity.Type())
+	return store.ListPage(ctx, d.Store, identity, opt...)
+}
+
+func (d *validateStore) Count(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.ListOption) (int, error) {
+
+	d.Log.Printf("count %s", identity.Type())
+	return store.Count(ctx, d.Store, identity, opt...)
+}
+
+func (d *validateStore) Watch(
+	ctx context.Context,
+	identity store.ObjectIdentity,
+	opt ...options.WatchOption) (<-chan store.Event, error) {
+
+	d.Log.Printf("watch %s", identity.Path())
+	return store.Watch(ctx, d.Store, identity, opt...)
+}
+
+// every object of the batch is validated before it is handed over
+// so an invalid op leaves the underlying store untouched
+func (d *validateStore) Batch(
+	ctx context.Context,
+	ops []store.Op) (store.ObjectList, error) {
+
+	d.Log.Printf("batch %d", len(ops))
+	for _, op := range ops {
+		err := op.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Type == store.OpDelete {
+			continue
+		}
+
+		err = store.Validate(op.Object)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return store.Batch(ctx, d.Store, ops)
+}
@@ -0,0 +1,32 @@
+package validate_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/wazofski/storz/generated"
+	"github.com/wazofski/storz/memory"
+	"github.com/wazofski/storz/store"
+	"github.com/wazofski/storz/validate"
+)
+
+func TestValidate(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Validate Suite")
+}
+
+var str store.Store
+var ctx context.Context
+
+var _ = BeforeSuite(func() {
+	sch := generated.Schema()
+
+	str = store.New(
+		sch,
+		validate.Factory(
+			store.New(sch, memory.Factory())))
+
+	ctx = context.Background()
+})
@@ -0,0 +1,113 @@
+package validate_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/wazofski/storz/generated"
+	"github.com/wazofski/storz/internal/constants"
+	"github.com/wazofski/storz/store"
+)
+
+func email(address string) *string {
+	return &address
+}
+
+var _ = Describe("validate", func() {
+
+	It("can CREATE valid objects", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("abc")
+		citizen.Spec().SetEmail(email("abc@storz"))
+		citizen.Spec().SetAge(42)
+		citizen.Spec().SetRole("mayor")
+
+		ret, err := str.Create(ctx, citizen)
+		Expect(err).To(BeNil())
+		Expect(ret).ToNot(BeNil())
+	})
+
+	It("cannot CREATE objects without required props", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetEmail(email("def@storz"))
+
+		_, err := str.Create(ctx, citizen)
+		Expect(errors.Is(err, constants.ErrInvalidObject)).To(BeTrue())
+
+		verr := &store.ValidationError{}
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		Expect(verr.Path).To(Equal("spec.name"))
+	})
+
+	It("cannot CREATE objects breaking the rules", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("def")
+		citizen.Spec().SetEmail(email("storz"))
+
+		_, err := str.Create(ctx, citizen)
+		Expect(errors.Is(err, constants.ErrInvalidObject)).To(BeTrue())
+
+		citizen.Spec().SetEmail(email("def@storz"))
+		citizen.Spec().SetAge(151)
+		_, err = str.Create(ctx, citizen)
+		Expect(errors.Is(err, constants.ErrInvalidObject)).To(BeTrue())
+
+		citizen.Spec().SetAge(-1)
+		_, err = str.Create(ctx, citizen)
+		Expect(errors.Is(err, constants.ErrInvalidObject)).To(BeTrue())
+
+		citizen.Spec().SetAge(0)
+		citizen.Spec().SetRole("king")
+		_, err = str.Create(ctx, citizen)
+		Expect(errors.Is(err, constants.ErrInvalidObject)).To(BeTrue())
+
+		citizen.Spec().SetRole("citizen")
+		citizen.Spec().SetNicknames([]string{"a", "b", "c", "d"})
+		_, err = str.Create(ctx, citizen)
+		Expect(errors.Is(err, constants.ErrInvalidObject)).To(BeTrue())
+
+		citizen.Spec().SetNicknames([]string{"a", "b", "c"})
+		_, err = str.Create(ctx, citizen)
+		Expect(err).To(BeNil())
+	})
+
+	It("cannot UPDATE objects breaking the rules", func() {
+		citizen := generated.CitizenFactory()
+		citizen.Spec().SetName("abc")
+		citizen.Spec().SetEmail(email("abc"))
+
+		_, err := str.Update(ctx, generated.CitizenIdentity("abc"), citizen)
+		Expect(errors.Is(err, constants.ErrInvalidObject)).To(BeTrue())
+
+		ret, err := str.Get(ctx, generated.CitizenIdentity("abc"))
+		Expect(err).To(BeNil())
+		Expect(*ret.(generated.Citizen).Spec().Email()).To(Equal("abc@storz"))
+	})
+
+	It("cannot BATCH objects breaking the rules", func() {
+		valid := generated.CitizenFactory()
+		valid.Spec().SetName("ghi")
+
+		invalid := generated.CitizenFactory()
+		invalid.Spec().SetName("jkl")
+		invalid.Spec().SetRole("king")
+
+		_, err := store.Batch(ctx, str, []store.Op{
+			store.CreateOp(valid),
+			store.CreateOp(invalid),
+		})
+		Expect(errors.Is(err, constants.ErrInvalidObject)).To(BeTrue())
+
+		_, err = str.Get(ctx, generated.CitizenIdentity("ghi"))
+		Expect(err).To(Equal(constants.ErrNoSuchObject))
+	})
+
+	It("can DELETE objects", func() {
+		err := str.Delete(ctx, generated.CitizenIdentity("abc"))
+		Expect(err).To(BeNil())
+
+		err = str.Delete(ctx, generated.CitizenIdentity("def"))
+		Expect(err).To(BeNil())
+	})
+})